@@ -1,11 +1,15 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"go/ast"
-	"maps"
 	"os"
 
 	"github.com/goccy/go-yaml"
+	yamlast "github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
 )
 
 type ExportType string
@@ -17,18 +21,117 @@ const (
 	ExportTypeFunction ExportType = "function"
 )
 
+// UnmarshalText unmarshals and validates the export type from text.
+func (et *ExportType) UnmarshalText(text []byte) error {
+	switch exportType := ExportType(text); exportType {
+	case ExportTypeType, ExportTypeVariable, ExportTypeConstant, ExportTypeFunction:
+		*et = exportType
+		return nil
+	default:
+		return fmt.Errorf("unknown export type %q", exportType)
+	}
+}
+
+// VariableMode defines how variables are re-exported.
+type VariableMode string
+
+const (
+	VariableModeCopy     VariableMode = "copy"     // Copy the value at initialization: var X = pkg.X
+	VariableModePointer  VariableMode = "pointer"  // Export a pointer to the variable: var XPtr = &pkg.X
+	VariableModeAccessor VariableMode = "accessor" // Export getter and setter functions: func X() T, func SetX(T)
+)
+
+// UnmarshalText unmarshals and validates the variable mode from text.
+func (m *VariableMode) UnmarshalText(text []byte) error {
+	switch mode := VariableMode(text); mode {
+	case VariableModeCopy, VariableModePointer, VariableModeAccessor:
+		*m = mode
+		return nil
+	default:
+		return fmt.Errorf("unknown variable mode %q", mode)
+	}
+}
+
+// CollisionStrategy defines how an export is handled whose name is already used
+// by another export.
+type CollisionStrategy string
+
+const (
+	CollisionStrategyError     CollisionStrategy = "error"      // Fail generation (default)
+	CollisionStrategyFirstWins CollisionStrategy = "first-wins" // Skip the later export
+	CollisionStrategyPrefix    CollisionStrategy = "prefix"     // Prefix the later export with its package name
+)
+
+// UnmarshalText unmarshals and validates the collision strategy from text.
+func (cs *CollisionStrategy) UnmarshalText(text []byte) error {
+	switch strategy := CollisionStrategy(text); strategy {
+	case CollisionStrategyError, CollisionStrategyFirstWins, CollisionStrategyPrefix:
+		*cs = strategy
+		return nil
+	default:
+		return fmt.Errorf("unknown collision strategy %q", strategy)
+	}
+}
+
+// Position describes a location in a configuration file.
+type Position struct {
+	File   string // Path of the configuration file
+	Line   int    // Line number, starting at 1 (0 if unknown)
+	Column int    // Column number, starting at 1 (0 if unknown)
+}
+
+// String returns the position in the form "file:line:column".
+func (p Position) String() string {
+	if p.Line == 0 {
+		return p.File
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
 // Config represents the overall configuration for the re-exporter.
 type Config struct {
 	Common  Export   `yaml:"common"`  // Common export configuration
 	Exports []Export `yaml:"exports"` // List of export configurations
+	Target  string   `yaml:"target"`  // Directory relative to the configuration file or import path of the generated package (default: the configuration directory)
+	Mirror  *Mirror  `yaml:"mirror"`  // Mirror a package tree using the common export configuration
+}
+
+// Mirror configures the generation of a package for every package below a source
+// directory, at the same relative location below a destination directory. Each
+// generated package re-exports its source package.
+type Mirror struct {
+	Source      string `yaml:"source"`      // Source root directory, relative to the configuration file
+	Destination string `yaml:"destination"` // Destination root directory, relative to the configuration file (default: the configuration directory)
 }
 
 // Export represents the export configuration for a specific module.
 type Export struct {
-	Import  string            `yaml:"import"`  // Module import path
-	Output  string            `yaml:"output"`  // Output file name
-	Exclude Exclusion         `yaml:"exclude"` // Exclusion rules for re-exports
-	Rename  map[string]string `yaml:"rename"`  // Rename symbol name during re-export
+	Import  string         `yaml:"import"`  // Module import path
+	Output  string         `yaml:"output"`  // Output file name
+	Include Inclusion      `yaml:"include"` // Inclusion rules for re-exports
+	Exclude Exclusion      `yaml:"exclude"` // Exclusion rules for re-exports
+	Rename  Renames        `yaml:"rename"`  // Rename symbol name during re-export
+	Naming  NamingStrategy `yaml:"naming"`  // Derive export names from symbol names, unless renamed
+
+	Variables  Variables         `yaml:"variables"`  // Re-export settings for variables
+	Collisions CollisionStrategy `yaml:"collisions"` // Handling of exports whose name is already used
+
+	Pos Position `yaml:"-"` // Position of the export in the configuration file
+}
+
+// Variables defines how variables are re-exported.
+type Variables struct {
+	Mode    VariableMode            `yaml:"mode"`    // Default mode for all variables (default: copy)
+	Symbols map[string]VariableMode `yaml:"symbols"` // Mode for specific variables by their original name
+}
+
+// Inclusion defines which symbols to re-export. Empty lists include everything,
+// otherwise a symbol must match every non-empty list to be re-exported. Exclusion
+// rules are applied to included symbols, so exclusions take precedence.
+type Inclusion struct {
+	Kinds []ExportType `yaml:"kinds"` // Only export symbols of these kinds
+	Names []Filter     `yaml:"names"` // Only export names matching these filters
+	Files []Filter     `yaml:"files"` // Only export names from files matching these filters (file name only without extension)
 }
 
 // Exclusion defines what kinds of symbols to exclude from re-exporting.
@@ -41,87 +144,188 @@ type Exclusion struct {
 	Files     []Filter `yaml:"files"`     // Do not export names from file matching these filters (file name only without extension)
 }
 
-// IncludeFile checks if the given file name is allowed based on the
-// exclusion rules. It returns true if the file is included, false if excluded.
+// IncludeFile checks if the given file name is allowed based on the inclusion
+// and exclusion rules. It returns true if the file is included, false if excluded.
 func (es *Export) IncludeFile(fileName string) bool {
-	for _, f := range es.Exclude.Files {
-		if f.Match(fileName) {
-			return false
-		}
+	// Check include filters first
+	if len(es.Include.Files) > 0 && !matchAny(es.Include.Files, fileName) {
+		return false
 	}
-	return true
+
+	return !matchAny(es.Exclude.Files, fileName)
 }
 
-// ExportAs determines the export name for a given identifier based on the
-// export configuration. It returns the new name and a boolean indicating
-// whether the identifier should be exported.
-func (es *Export) ExportAs(name *ast.Ident, exportType ExportType) (string, bool) {
-	// Validate name and exportability
-	if name == nil || name.Name == "" || !name.IsExported() {
-		return "", false
+// VariableMode returns the mode a variable with the given original name is
+// re-exported with.
+func (es *Export) VariableMode(name string) VariableMode {
+	if mode, ok := es.Variables.Symbols[name]; ok {
+		return mode
 	}
+	if es.Variables.Mode != "" {
+		return es.Variables.Mode
+	}
+	return VariableModeCopy
+}
 
-	// Check type-based exclusions
-	if exportType == ExportTypeType && es.Exclude.Types ||
-		exportType == ExportTypeVariable && es.Exclude.Variables ||
-		exportType == ExportTypeConstant && es.Exclude.Constants ||
-		exportType == ExportTypeFunction && es.Exclude.Functions {
-		return name.Name, false
+// ExportAs determines the export name for a given identifier from the package
+// named pkg based on the export configuration. It returns the new name and a
+// boolean indicating whether the identifier should be exported.
+func (es *Export) ExportAs(name *ast.Ident, exportType ExportType, pkg string) (string, bool, error) {
+	d, err := es.Explain(name, exportType, pkg)
+	return d.Name, d.Exported, err
+}
+
+// FromFile loads the exporter configuration from the given YAML file. The common
+// settings of the root configuration, if not nil, are the defaults of the common
+// settings of the file.
+func FromFile(path string, root *Root) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
 	}
 
-	// Check exclude filters first
-	for _, f := range es.Exclude.Names {
-		if f.Match(name.Name) {
-			return name.Name, false
+	return Parse(path, b, root)
+}
+
+// Parse parses the exporter configuration from YAML source read from the file at
+// path, which is used for positions. The common settings of the root configuration,
+// if not nil, are the defaults of the common settings of the source.
+func Parse(path string, b []byte, root *Root) (*Config, error) {
+	var config Config
+	err := yaml.UnmarshalWithOptions(b, &config, yaml.Strict())
+	if err != nil {
+		return nil, fileError(path, err)
+	}
+
+	// Record the positions of the exports for error reporting
+	file, err := parser.ParseBytes(b, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := setPositions(path, file, "$", &config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	config.setFile(path)
+
+	var defaults Export
+	if root != nil {
+		defaults = root.Common
+	}
+	if err := config.resolve(defaults); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &config, nil
+}
+
+// resolve merges the defaults into the common settings and the common settings
+// into the exports, and sets the defaults of unset settings.
+func (c *Config) resolve(defaults Export) error {
+	c.Common.merge(defaults)
+	if c.Common.Output == "" {
+		c.Common.Output = "exported.go"
+	}
+
+	if c.Mirror != nil {
+		if c.Mirror.Source == "" {
+			return errors.New("mirror: source must be set")
+		}
+		if c.Mirror.Destination == "" {
+			c.Mirror.Destination = "."
 		}
 	}
 
-	// Apply renaming if applicable
-	newName := name.Name
-	if renamed, ok := es.Rename[name.Name]; ok {
-		newName = renamed
+	for i := range c.Exports {
+		c.Exports[i].merge(c.Common)
 	}
 
-	// Not matched by specified include filter
-	return newName, true
+	return nil
 }
 
-// FromFile loads the exporter configuration from the given YAML file.
-func FromFile(path string) (*Config, error) {
-	b, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
+// merge merges the common settings into the export. Settings of the export take
+// precedence, lists of exclusions and rename rules are combined.
+func (es *Export) merge(common Export) {
+	// Merge Include, lists of the export replace the common lists
+	if len(es.Include.Kinds) == 0 {
+		es.Include.Kinds = common.Include.Kinds
+	}
+	if len(es.Include.Names) == 0 {
+		es.Include.Names = common.Include.Names
+	}
+	if len(es.Include.Files) == 0 {
+		es.Include.Files = common.Include.Files
 	}
 
-	var config Config
-	err = yaml.Unmarshal(b, &config)
-	if err != nil {
-		return nil, err
+	// Merge Exclude
+	es.Exclude.Types = es.Exclude.Types || common.Exclude.Types
+	es.Exclude.Variables = es.Exclude.Variables || common.Exclude.Variables
+	es.Exclude.Constants = es.Exclude.Constants || common.Exclude.Constants
+	es.Exclude.Functions = es.Exclude.Functions || common.Exclude.Functions
+	es.Exclude.Names = append(es.Exclude.Names, common.Exclude.Names...)
+	es.Exclude.Files = append(es.Exclude.Files, common.Exclude.Files...)
+
+	if es.Output == "" {
+		es.Output = common.Output
+	}
+
+	if es.Naming == "" {
+		es.Naming = common.Naming
+	}
+
+	// Merge Rename, rules of the export are evaluated first
+	es.Rename = append(es.Rename, common.Rename...)
+
+	if es.Collisions == "" {
+		es.Collisions = common.Collisions
 	}
 
-	// Set defaults and merge common settings
-	if config.Common.Output == "" {
-		config.Common.Output = "exported.go"
+	// Merge Variables, per-export settings take precedence
+	if es.Variables.Mode == "" {
+		es.Variables.Mode = common.Variables.Mode
 	}
+	for name, mode := range common.Variables.Symbols {
+		if _, ok := es.Variables.Symbols[name]; !ok {
+			if es.Variables.Symbols == nil {
+				es.Variables.Symbols = make(map[string]VariableMode)
+			}
+			es.Variables.Symbols[name] = mode
+		}
+	}
+}
 
+// setPositions sets the position of each export from the parsed YAML source. The
+// configuration is located at the YAML path prefix (e.g. "$").
+func setPositions(path string, file *yamlast.File, prefix string, config *Config) error {
 	for i := range config.Exports {
-		es := &config.Exports[i]
-
-		// Merge Exclude
-		es.Exclude.Types = es.Exclude.Types || config.Common.Exclude.Types
-		es.Exclude.Variables = es.Exclude.Variables || config.Common.Exclude.Variables
-		es.Exclude.Constants = es.Exclude.Constants || config.Common.Exclude.Constants
-		es.Exclude.Functions = es.Exclude.Functions || config.Common.Exclude.Functions
-		es.Exclude.Names = append(es.Exclude.Names, config.Common.Exclude.Names...)
-		es.Exclude.Files = append(es.Exclude.Files, config.Common.Exclude.Files...)
-
-		if es.Output == "" {
-			es.Output = config.Common.Output
+		config.Exports[i].Pos = Position{File: path}
+
+		p, err := yaml.PathString(fmt.Sprintf("%s.exports[%d]", prefix, i))
+		if err != nil {
+			return err
+		}
+		node, err := p.FilterFile(file)
+		if err != nil {
+			return err
 		}
 
-		// Merge Rename
-		maps.Copy(es.Rename, config.Common.Rename)
+		pos := nodePosition(node)
+		config.Exports[i].Pos.Line, config.Exports[i].Pos.Column = pos.Line, pos.Column
 	}
 
-	return &config, nil
+	return nil
+}
+
+// nodePosition returns the position of a YAML node. For mappings, the position
+// of the first key is returned instead of the position of its value indicator.
+func nodePosition(node yamlast.Node) *token.Position {
+	switch n := node.(type) {
+	case *yamlast.MappingNode:
+		if len(n.Values) > 0 {
+			return n.Values[0].Key.GetToken().Position
+		}
+	case *yamlast.MappingValueNode:
+		return n.Key.GetToken().Position
+	}
+	return node.GetToken().Position
 }
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+)
+
+// nodeError is an error located at a node of the YAML source.
+type nodeError struct {
+	line   int
+	column int
+	err    error
+}
+
+// newNodeError returns err located at node.
+func newNodeError(node ast.Node, err error) error {
+	pos := nodePosition(node)
+	return &nodeError{line: pos.Line, column: pos.Column, err: err}
+}
+
+func (e *nodeError) Error() string {
+	return fmt.Sprintf("%d:%d: %v", e.line, e.column, e.err)
+}
+
+func (e *nodeError) Unwrap() error {
+	return e.err
+}
+
+// fileError prefixes err with the path of the configuration file and the position
+// of the error in it, if known.
+func fileError(path string, err error) error {
+	var (
+		ne *nodeError
+		ye yaml.Error
+	)
+	switch {
+	case errors.As(err, &ne):
+		pos := Position{File: path, Line: ne.line, Column: ne.column}
+		return fmt.Errorf("%s: %w", pos, ne.err)
+	case errors.As(err, &ye) && ye.GetToken() != nil:
+		tk := ye.GetToken()
+		pos := Position{File: path, Line: tk.Position.Line, Column: tk.Position.Column}
+		return fmt.Errorf("%s: %s", pos, ye.GetMessage())
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"go/ast"
+	"slices"
+)
+
+// Decision describes how an export configuration decided about a symbol.
+type Decision struct {
+	Name     string   // The export name, or the original name if not exported
+	Exported bool     // Whether the symbol is exported
+	Steps    []string // The rules deciding about the symbol, in order of evaluation
+}
+
+// step records a rule deciding about the symbol.
+func (d *Decision) step(format string, args ...any) {
+	d.Steps = append(d.Steps, fmt.Sprintf(format, args...))
+}
+
+// ExplainFile checks if the given file name is allowed based on the inclusion and
+// exclusion rules, like IncludeFile. It also returns the rule deciding about it.
+func (es *Export) ExplainFile(fileName string) (bool, string) {
+	// Check include filters first
+	if len(es.Include.Files) > 0 && !matchAny(es.Include.Files, fileName) {
+		return false, fmt.Sprintf("file %s matches no include.files filter", fileName)
+	}
+
+	if f := firstMatch(es.Exclude.Files, fileName); f != nil {
+		return false, fmt.Sprintf("file %s is excluded by exclude.files filter %q", fileName, f)
+	}
+	return true, fmt.Sprintf("file %s is included", fileName)
+}
+
+// Explain determines the export name for a given identifier from the package named
+// pkg like ExportAs. The decision also records the rules deciding about it.
+func (es *Export) Explain(name *ast.Ident, exportType ExportType, pkg string) (Decision, error) {
+	var d Decision
+
+	// Validate name and exportability
+	if name == nil || name.Name == "" || !name.IsExported() {
+		d.step("%s is not exported by its package", name)
+		return d, nil
+	}
+	d.Name = name.Name
+
+	// Check include rules first, symbols not included are never exported
+	if len(es.Include.Kinds) > 0 && !slices.Contains(es.Include.Kinds, exportType) {
+		d.step("kind %s is not listed in include.kinds", exportType)
+		return d, nil
+	}
+	if len(es.Include.Names) > 0 {
+		f := firstMatch(es.Include.Names, name.Name)
+		if f == nil {
+			d.step("name matches no include.names filter")
+			return d, nil
+		}
+		d.step("name is included by include.names filter %q", f)
+	}
+
+	// Check type-based exclusions
+	if exportType == ExportTypeType && es.Exclude.Types ||
+		exportType == ExportTypeVariable && es.Exclude.Variables ||
+		exportType == ExportTypeConstant && es.Exclude.Constants ||
+		exportType == ExportTypeFunction && es.Exclude.Functions {
+		d.step("kind %s is excluded", exportType)
+		return d, nil
+	}
+
+	// Check exclude filters
+	if f := firstMatch(es.Exclude.Names, name.Name); f != nil {
+		d.step("name is excluded by exclude.names filter %q", f)
+		return d, nil
+	}
+
+	// Apply renaming if applicable
+	newName, rule, err := es.Rename.apply(RenameData{Name: name.Name, Package: pkg, Kind: string(exportType)})
+	if err != nil {
+		return d, err
+	}
+
+	// Otherwise apply the naming strategy
+	if rule != nil {
+		d.step("renamed to %s by rename rule %q", newName, &rule.Filter)
+	} else {
+		newName = es.Naming.Apply(name.Name, pkg)
+		if newName != name.Name {
+			d.step("renamed to %s by naming strategy %s", newName, es.Naming)
+		}
+	}
+
+	d.Name, d.Exported = newName, true
+	return d, nil
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"regexp"
 	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
 )
 
 // Filter represents a filter that can match exact text or a regular expression.
@@ -11,17 +14,41 @@ import (
 type Filter struct {
 	text  string
 	regex *regexp.Regexp
+	usage *usage // Tracks matches of all copies of the filter, nil if not unmarshaled from YAML
 }
 
 // Match checks if the given string matches the filter.
 func (f *Filter) Match(s string) bool {
-	// Exact match if no regex is defined
-	if f.regex == nil {
-		return s == f.text
+	// Exact match if no regex is defined, otherwise regex match
+	matched := s == f.text
+	if f.regex != nil {
+		matched = f.regex.MatchString(s)
+	}
+
+	if matched && f.usage != nil {
+		f.usage.matched = true
+	}
+	return matched
+}
+
+// matchAny checks if the given string matches any of the filters.
+func matchAny(fs []Filter, s string) bool {
+	return firstMatch(fs, s) != nil
+}
+
+// firstMatch returns the first filter matching the given string, or nil if none matches.
+func firstMatch(fs []Filter, s string) *Filter {
+	for i := range fs {
+		if fs[i].Match(s) {
+			return &fs[i]
+		}
 	}
+	return nil
+}
 
-	// Regex match
-	return f.regex.MatchString(s)
+// String returns the text of the filter as configured.
+func (f *Filter) String() string {
+	return f.text
 }
 
 // UnmarshalText unmarshals the filter from text.
@@ -37,3 +64,20 @@ func (f *Filter) UnmarshalText(text []byte) error {
 
 	return err
 }
+
+// UnmarshalYAML unmarshals the filter from a YAML node, reporting invalid regular
+// expressions at the position of the node. The position is recorded to report
+// filters which never match.
+func (f *Filter) UnmarshalYAML(node ast.Node) error {
+	var text string
+	if err := yaml.NodeToValue(node, &text); err != nil {
+		return err
+	}
+	if err := f.UnmarshalText([]byte(text)); err != nil {
+		return newNodeError(node, err)
+	}
+
+	pos := nodePosition(node)
+	f.usage = &usage{pos: Position{Line: pos.Line, Column: pos.Column}}
+	return nil
+}
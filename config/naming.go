@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NamingStrategy defines how export names are derived from symbol names.
+type NamingStrategy string
+
+const (
+	NamingStrategyKeep    NamingStrategy = "keep"    // Keep the symbol names (default)
+	NamingStrategyPackage NamingStrategy = "package" // Qualify names with the package name: cache.New becomes NewCache, cache.Config becomes CacheConfig
+)
+
+// UnmarshalText unmarshals and validates the naming strategy from text.
+func (ns *NamingStrategy) UnmarshalText(text []byte) error {
+	switch strategy := NamingStrategy(text); strategy {
+	case NamingStrategyKeep, NamingStrategyPackage:
+		*ns = strategy
+		return nil
+	default:
+		return fmt.Errorf("unknown naming strategy %q", strategy)
+	}
+}
+
+// Apply derives the export name for the symbol name from the package named pkg.
+func (ns NamingStrategy) Apply(name string, pkg string) string {
+	if ns != NamingStrategyPackage || pkg == "" {
+		return name
+	}
+
+	prefix := title(pkg)
+
+	// Constructors keep their prefix: New, NewClient and NewCacheClient become
+	// NewCache, NewCacheClient and NewCacheClient
+	if rest, ok := cutWord(name, "New"); ok {
+		if _, ok := cutWord(rest, prefix); ok {
+			return name
+		}
+		return name[:len(name)-len(rest)] + prefix + rest
+	}
+
+	// Other names are prefixed unless they already start with the package name,
+	// which keeps its spelling: Config and CacheConfig become CacheConfig, and
+	// HTTPServer stays HTTPServer in package http
+	if _, ok := cutWord(name, prefix); ok {
+		return name
+	}
+	return prefix + name
+}
+
+// cutWord removes the prefix from s, ignoring case, if it is followed by the end of
+// s or the start of a new word in camel case. An upper case letter following an
+// upper case prefix only starts a new word if a lower case letter follows it, since
+// the acronym continues otherwise: the prefix "Http" is cut from HTTPServer, but
+// not from HTTPS. Otherwise s is returned unchanged.
+func cutWord(s string, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+	rest := s[len(prefix):]
+	if rest == "" {
+		return rest, true
+	}
+
+	r, size := utf8.DecodeRuneInString(rest)
+	switch {
+	case unicode.IsDigit(r):
+		return rest, true
+	case !unicode.IsUpper(r):
+		return s, false
+	}
+
+	// Within an acronym, the new word starts with its last upper case letter
+	if last, _ := utf8.DecodeLastRuneInString(s[:len(prefix)]); unicode.IsUpper(last) {
+		if next, _ := utf8.DecodeRuneInString(rest[size:]); !unicode.IsLower(next) {
+			return s, false
+		}
+	}
+	return rest, true
+}
+
+// title returns s with its first letter in upper case.
+func title(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
@@ -0,0 +1,40 @@
+package config
+
+import "testing"
+
+func TestNamingStrategyApply(t *testing.T) {
+	tests := []struct {
+		strategy NamingStrategy
+		pkg      string
+		name     string
+		want     string
+	}{
+		{NamingStrategyKeep, "cache", "Config", "Config"},
+		{NamingStrategyPackage, "", "Config", "Config"},
+		{NamingStrategyPackage, "cache", "Config", "CacheConfig"},
+		{NamingStrategyPackage, "cache", "CacheConfig", "CacheConfig"},
+		{NamingStrategyPackage, "cache", "Cache", "Cache"},
+		{NamingStrategyPackage, "cache", "Cache2", "Cache2"},
+		{NamingStrategyPackage, "cache", "Cached", "CacheCached"},
+		{NamingStrategyPackage, "cache", "New", "NewCache"},
+		{NamingStrategyPackage, "cache", "NewClient", "NewCacheClient"},
+		{NamingStrategyPackage, "cache", "NewCacheClient", "NewCacheClient"},
+		{NamingStrategyPackage, "http", "NewHTTPServer", "NewHTTPServer"},
+		{NamingStrategyPackage, "http", "NewServer", "NewHttpServer"},
+		{NamingStrategyPackage, "cache", "Newer", "CacheNewer"},
+		{NamingStrategyPackage, "aa", "AAClient", "AAClient"},
+		{NamingStrategyPackage, "aa", "NewAAClient", "NewAAClient"},
+		{NamingStrategyPackage, "aa", "Aardvark", "AaAardvark"},
+		{NamingStrategyPackage, "aa", "AAA", "AaAAA"},
+		{NamingStrategyPackage, "http", "HTTPServer", "HTTPServer"},
+		{NamingStrategyPackage, "http", "HTTP", "HTTP"},
+		{NamingStrategyPackage, "http", "HTTPS", "HttpHTTPS"},
+		{NamingStrategyPackage, "http", "HTTPSServer", "HttpHTTPSServer"},
+		{NamingStrategyPackage, "http", "HttpServer", "HttpServer"},
+	}
+	for _, tt := range tests {
+		if got := tt.strategy.Apply(tt.name, tt.pkg); got != tt.want {
+			t.Errorf("%s.Apply(%q, %q) = %q, want %q", tt.strategy, tt.name, tt.pkg, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+)
+
+// RenameData is passed to rename templates.
+type RenameData struct {
+	Name    string   // The original name of the symbol
+	Package string   // The name of the source package
+	Kind    string   // The kind of the symbol (type, variable, constant or function)
+	Groups  []string // Submatches of a regular expression filter, Groups[0] is the whole match
+}
+
+// RenameRule renames symbols matching a filter.
+//
+// For regular expression filters, the matched text is replaced using the regexp
+// syntax, so "$1" refers to the first capture group. New names containing "{{"
+// are executed as templates with RenameData and sprig functions instead.
+type RenameRule struct {
+	Filter Filter // Filter matching the original name
+	To     string // The new name or template
+	tpl    *template.Template
+}
+
+// Apply renames the symbol described by data if the rule matches its name.
+// It returns the new name and whether the rule matched.
+func (r *RenameRule) Apply(data RenameData) (string, bool, error) {
+	if !r.Filter.Match(data.Name) {
+		return "", false, nil
+	}
+
+	if r.tpl != nil {
+		if r.Filter.regex != nil {
+			data.Groups = r.Filter.regex.FindStringSubmatch(data.Name)
+		} else {
+			data.Groups = []string{data.Name}
+		}
+
+		sb := &strings.Builder{}
+		if err := r.tpl.Execute(sb, data); err != nil {
+			return "", true, fmt.Errorf("rename %q: %w", r.Filter.text, err)
+		}
+		return sb.String(), true, nil
+	}
+
+	if r.Filter.regex != nil {
+		return r.Filter.regex.ReplaceAllString(data.Name, r.To), true, nil
+	}
+	return r.To, true, nil
+}
+
+// Renames is an ordered list of rename rules, configured as a YAML mapping from
+// filters to new names. The first matching rule is applied.
+type Renames []RenameRule
+
+// Apply renames the symbol described by data using the first matching rule. It
+// returns the new name and whether a rule matched, the original name is returned
+// if no rule matches.
+func (rs Renames) Apply(data RenameData) (string, bool, error) {
+	name, rule, err := rs.apply(data)
+	return name, rule != nil, err
+}
+
+// apply renames the symbol described by data using the first matching rule. It
+// returns the new name and the matching rule, or the original name and nil if no
+// rule matches.
+func (rs Renames) apply(data RenameData) (string, *RenameRule, error) {
+	for i := range rs {
+		name, ok, err := rs[i].Apply(data)
+		if err != nil {
+			return name, &rs[i], err
+		}
+		if ok {
+			if err := validateName(name); err != nil {
+				return name, &rs[i], fmt.Errorf("rename %q: %w", rs[i].Filter.text, err)
+			}
+			return name, &rs[i], nil
+		}
+	}
+	return data.Name, nil, nil
+}
+
+// UnmarshalYAML unmarshals the rename rules from a YAML mapping, keeping their order.
+// New names of plain rules must be valid exported identifiers.
+func (rs *Renames) UnmarshalYAML(node ast.Node) error {
+	var values []*ast.MappingValueNode
+	switch n := node.(type) {
+	case *ast.NullNode:
+	case *ast.MappingNode:
+		values = n.Values
+	case *ast.MappingValueNode:
+		values = []*ast.MappingValueNode{n}
+	default:
+		return newNodeError(node, errors.New("rename must be a mapping from filters to new names"))
+	}
+
+	*rs = make(Renames, 0, len(values))
+	for _, value := range values {
+		var r RenameRule
+		if err := r.Filter.UnmarshalYAML(value.Key); err != nil {
+			return err
+		}
+
+		if err := yaml.NodeToValue(value.Value, &r.To); err != nil || value.Value.Type() != ast.StringType {
+			return newNodeError(value.Value, fmt.Errorf("rename %q: new name must be a string", r.Filter.text))
+		}
+
+		switch {
+		case strings.Contains(r.To, "{{"):
+			tpl, err := template.New(r.Filter.text).Funcs(sprig.TxtFuncMap()).Parse(r.To)
+			if err != nil {
+				return newNodeError(value.Value, fmt.Errorf("rename %q: %w", r.Filter.text, err))
+			}
+			r.tpl = tpl
+		case r.Filter.regex == nil:
+			// New names of regular expression rules may contain references to groups
+			if err := validateName(r.To); err != nil {
+				return newNodeError(value.Value, fmt.Errorf("rename %q: %w", r.Filter.text, err))
+			}
+		}
+
+		*rs = append(*rs, r)
+	}
+
+	return nil
+}
+
+// validateName checks that name can be used as the name of an export.
+func validateName(name string) error {
+	switch {
+	case token.IsKeyword(name):
+		return fmt.Errorf("new name %q is a keyword", name)
+	case !token.IsIdentifier(name):
+		return fmt.Errorf("new name %q is not a valid identifier", name)
+	case !token.IsExported(name):
+		return fmt.Errorf("new name %q is not exported", name)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+// parseRenames decodes the rename rules of a YAML mapping.
+func parseRenames(t *testing.T, src string) (Renames, error) {
+	t.Helper()
+
+	var v struct {
+		Rename Renames `yaml:"rename"`
+	}
+	err := yaml.UnmarshalWithOptions([]byte("rename:\n"+src), &v, yaml.Strict())
+	return v.Rename, err
+}
+
+func TestRenamesApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   string
+		symbol  string
+		want    string
+		matched bool
+		err     string
+	}{
+		{
+			name:    "exact",
+			rules:   "  Old: New\n",
+			symbol:  "Old",
+			want:    "New",
+			matched: true,
+		},
+		{
+			name:   "no match",
+			rules:  "  Old: New\n",
+			symbol: "Other",
+			want:   "Other",
+		},
+		{
+			name:    "first rule wins",
+			rules:   "  /^Legacy.*/: First\n  LegacyClient: Second\n",
+			symbol:  "LegacyClient",
+			want:    "First",
+			matched: true,
+		},
+		{
+			name:    "order of the mapping",
+			rules:   "  LegacyClient: Second\n  /^Legacy.*/: First\n",
+			symbol:  "LegacyClient",
+			want:    "Second",
+			matched: true,
+		},
+		{
+			name:    "later rule",
+			rules:   "  /^Legacy.*/: First\n  Client: Second\n",
+			symbol:  "Client",
+			want:    "Second",
+			matched: true,
+		},
+		{
+			name:    "capture group",
+			rules:   "  /^Legacy(.*)$/: $1\n",
+			symbol:  "LegacyClient",
+			want:    "Client",
+			matched: true,
+		},
+		{
+			name:    "template group",
+			rules:   "  /^Legacy(.*)$/: 'V1{{ index .Groups 1 }}'\n",
+			symbol:  "LegacyClient",
+			want:    "V1Client",
+			matched: true,
+		},
+		{
+			name:    "template data",
+			rules:   "  /.*/: '{{ .Package | title }}{{ .Name }}'\n",
+			symbol:  "Client",
+			want:    "StoreClient",
+			matched: true,
+		},
+		{
+			name:    "keyword",
+			rules:   "  /^Old(.*)$/: '{{ index .Groups 1 | lower }}'\n",
+			symbol:  "OldType",
+			want:    "type",
+			matched: true,
+			err:     `new name "type" is a keyword`,
+		},
+		{
+			name:    "invalid identifier",
+			rules:   "  /^Old(.*)$/: 1$1\n",
+			symbol:  "OldName",
+			want:    "1Name",
+			matched: true,
+			err:     `new name "1Name" is not a valid identifier`,
+		},
+		{
+			name:    "unexported",
+			rules:   "  /^Old(.*)$/: x$1\n",
+			symbol:  "OldName",
+			want:    "xName",
+			matched: true,
+			err:     `new name "xName" is not exported`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs, err := parseRenames(t, tt.rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, matched, err := rs.Apply(RenameData{Name: tt.symbol, Package: "store", Kind: "type"})
+			if got != tt.want || matched != tt.matched {
+				t.Errorf("Apply(%q) = %q, %t, want %q, %t", tt.symbol, got, matched, tt.want, tt.matched)
+			}
+			if tt.err == "" && err != nil {
+				t.Errorf("Apply(%q) returned error: %v", tt.symbol, err)
+			}
+			if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Errorf("Apply(%q) error = %v, want %q", tt.symbol, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRenamesUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		want  []string // Filters in order
+		err   string
+	}{
+		{
+			name:  "order",
+			rules: "  B: X\n  /^A/: Y\n  C: Z\n",
+			want:  []string{"B", "/^A/", "C"},
+		},
+		{
+			name:  "empty",
+			rules: "  {}\n",
+			want:  []string{},
+		},
+		{
+			name:  "keyword",
+			rules: "  Old: func\n",
+			err:   `2:8: rename "Old": new name "func" is a keyword`,
+		},
+		{
+			name:  "invalid identifier",
+			rules: "  Old: New-Name\n",
+			err:   `2:8: rename "Old": new name "New-Name" is not a valid identifier`,
+		},
+		{
+			name:  "unexported",
+			rules: "  Old: new\n",
+			err:   `2:8: rename "Old": new name "new" is not exported`,
+		},
+		{
+			name:  "regex result is not validated",
+			rules: "  /^Old(.*)$/: $1\n",
+			want:  []string{"/^Old(.*)$/"},
+		},
+		{
+			name:  "not a string",
+			rules: "  Old: [New]\n",
+			err:   `rename "Old": new name must be a string`,
+		},
+		{
+			name:  "invalid template",
+			rules: "  Old: '{{ .Name'\n",
+			err:   `rename "Old"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs, err := parseRenames(t, tt.rules)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, 0, len(rs))
+			for _, r := range rs {
+				got = append(got, r.Filter.String())
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("filters = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
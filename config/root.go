@@ -0,0 +1,89 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/parser"
+)
+
+// RootFileName is the file name of the root configuration.
+const RootFileName = ".reexporter.yaml"
+
+// Root is the project-level configuration. Its common settings are the defaults of
+// all configuration files, which may override them. Facades can be listed centrally
+// instead of in configuration files next to them.
+type Root struct {
+	Common  Export   `yaml:"common"`  // Default common export configuration of all configurations
+	Facades []Config `yaml:"facades"` // Facade configurations, paths are relative to the root configuration file
+
+	Path string `yaml:"-"` // Path of the root configuration file
+}
+
+// FindRoot looks for the root configuration file in dir and its parent directories
+// and loads it. It returns nil if there is no root configuration file.
+func FindRoot(dir string) (*Root, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		path := filepath.Join(dir, RootFileName)
+		if _, err := os.Stat(path); err == nil {
+			return RootFromFile(path)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// RootFromFile loads the root configuration from the given YAML file.
+func RootFromFile(path string) (*Root, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var root Root
+	err = yaml.UnmarshalWithOptions(b, &root, yaml.Strict())
+	if err != nil {
+		return nil, fileError(path, err)
+	}
+	root.Path = path
+
+	file, err := parser.ParseBytes(b, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	root.Common.setFile(path)
+	for i := range root.Facades {
+		facade := &root.Facades[i]
+		facade.setFile(path)
+
+		// Facades are not located next to the root configuration
+		if facade.Target == "" {
+			return nil, fmt.Errorf("%s: facades[%d]: target must be set", path, i)
+		}
+
+		if err := setPositions(path, file, fmt.Sprintf("$.facades[%d]", i), facade); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if err := facade.resolve(root.Common); err != nil {
+			return nil, fmt.Errorf("%s: facades[%d]: %w", path, i, err)
+		}
+	}
+
+	return &root, nil
+}
@@ -0,0 +1,14 @@
+package config
+
+import _ "embed"
+
+// Schema is the JSON Schema of exported.yaml files, for editor completion and
+// validation.
+//
+//go:embed schema.json
+var Schema []byte
+
+// RootSchema is the JSON Schema of the root configuration file.
+//
+//go:embed root.schema.json
+var RootSchema []byte
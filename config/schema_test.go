@@ -0,0 +1,49 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// yamlKeys returns the YAML keys of the fields of the struct type t.
+func yamlKeys(t reflect.Type) []string {
+	var keys []string
+	for i := range t.NumField() {
+		if key, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ","); key != "" && key != "-" {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestSchemaProperties(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema []byte
+		typ    reflect.Type
+	}{
+		{"schema.json", Schema, reflect.TypeFor[Config]()},
+		{"root.schema.json", RootSchema, reflect.TypeFor[Root]()},
+	}
+	for _, tt := range tests {
+		var schema struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		}
+		if err := json.Unmarshal(tt.schema, &schema); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		var got []string
+		for key := range schema.Properties {
+			got = append(got, key)
+		}
+		slices.Sort(got)
+		if want := yamlKeys(tt.typ); !slices.Equal(got, want) {
+			t.Errorf("%s: properties = %q, want %q", tt.name, got, want)
+		}
+	}
+}
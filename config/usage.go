@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"iter"
+)
+
+// usage tracks whether a filter matched any symbol.
+type usage struct {
+	pos     Position // Position of the filter in the configuration file
+	matched bool     // Whether the filter matched any symbol
+}
+
+// Unused is a filter or rename rule which never matched any symbol.
+type Unused struct {
+	Pos     Position // Position of the entry in the configuration file
+	Setting string   // The setting containing the entry (e.g. "exclude.names")
+	Filter  string   // The filter of the entry
+	Config  int      // Index of the first configuration passed to UnusedEntries containing the entry
+}
+
+// String returns a description of the unused entry.
+func (u Unused) String() string {
+	return fmt.Sprintf("%s: %s entry %q never matched", u.Pos, u.Setting, u.Filter)
+}
+
+// UnusedEntries returns the filters and rename rules of the configurations which
+// never matched any symbol, in order of appearance. Entries shared by several
+// configurations, like the common settings of the root configuration, are only
+// reported if they never matched in any of them.
+func UnusedEntries(configs ...*Config) []Unused {
+	var unused []Unused
+	seen := make(map[*usage]bool)
+	for i, c := range configs {
+		for setting, f := range c.entries() {
+			if f.usage == nil || seen[f.usage] {
+				continue
+			}
+			seen[f.usage] = true
+
+			if !f.usage.matched {
+				unused = append(unused, Unused{Pos: f.usage.pos, Setting: setting, Filter: f.text, Config: i})
+			}
+		}
+	}
+	return unused
+}
+
+// setFile sets the file of the positions of all filters without one.
+func (es *Export) setFile(path string) {
+	for _, f := range es.entries() {
+		if f.usage != nil && f.usage.pos.File == "" {
+			f.usage.pos.File = path
+		}
+	}
+}
+
+// setFile sets the file of the positions of all filters of the common settings and
+// all exports without one.
+func (c *Config) setFile(path string) {
+	c.Common.setFile(path)
+	for i := range c.Exports {
+		c.Exports[i].setFile(path)
+	}
+}
+
+// entries iterates over the filters of the common settings and all exports by the
+// name of their setting.
+func (c *Config) entries() iter.Seq2[string, *Filter] {
+	return func(yield func(string, *Filter) bool) {
+		for _, es := range append([]Export{c.Common}, c.Exports...) {
+			for setting, f := range es.entries() {
+				if !yield(setting, f) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// entries iterates over the filters of the export by the name of their setting.
+// Rename rules are represented by their filters.
+func (es *Export) entries() iter.Seq2[string, *Filter] {
+	return func(yield func(string, *Filter) bool) {
+		lists := []struct {
+			setting string
+			filters []Filter
+		}{
+			{"include.names", es.Include.Names},
+			{"include.files", es.Include.Files},
+			{"exclude.names", es.Exclude.Names},
+			{"exclude.files", es.Exclude.Files},
+		}
+		for _, l := range lists {
+			for i := range l.filters {
+				if !yield(l.setting, &l.filters[i]) {
+					return
+				}
+			}
+		}
+
+		for i := range es.Rename {
+			if !yield("rename", &es.Rename[i].Filter) {
+				return
+			}
+		}
+	}
+}
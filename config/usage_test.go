@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// unusedStrings returns the descriptions of the unused entries of the configurations.
+func unusedStrings(configs ...*Config) []string {
+	var got []string
+	for _, u := range UnusedEntries(configs...) {
+		got = append(got, u.String())
+	}
+	return got
+}
+
+func TestUnusedEntries(t *testing.T) {
+	src := `common:
+  exclude:
+    names: [Internal, Legacy]
+exports:
+  - import: example.com/m/a
+    include:
+      names: [Client, /^New/]
+    rename:
+      Old: New
+  - import: example.com/m/b
+`
+	c, err := Parse("exported.yaml", []byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Common entries are used if they match in any export
+	c.Exports[0].Include.Names[0].Match("Client")
+	c.Exports[1].Exclude.Names[0].Match("Internal")
+
+	want := []string{
+		`exported.yaml:3:23: exclude.names entry "Legacy" never matched`,
+		`exported.yaml:7:23: include.names entry "/^New/" never matched`,
+		`exported.yaml:9:7: rename entry "Old" never matched`,
+	}
+	if got := unusedStrings(c); !slices.Equal(got, want) {
+		t.Errorf("UnusedEntries() = %q, want %q", got, want)
+	}
+
+	// Rename rules are used if they match
+	if _, _, err := c.Exports[0].Rename.Apply(RenameData{Name: "Old"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := unusedStrings(c); !slices.Equal(got, want[:2]) {
+		t.Errorf("UnusedEntries() = %q, want %q", got, want[:2])
+	}
+}
+
+func TestUnusedEntriesOfRoot(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, RootFileName)
+	if err := os.WriteFile(path, []byte("common:\n  exclude:\n    names: [Internal, Legacy]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	root, err := RootFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var configs []*Config
+	for _, name := range []string{"a.yaml", "b.yaml"} {
+		c, err := Parse(name, []byte("exports:\n  - import: example.com/m/a\n"), root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		configs = append(configs, c)
+	}
+
+	// Entries of the root are reported once, with the first configuration, unless
+	// they match in any configuration
+	configs[1].Exports[0].Exclude.Names[0].Match("Internal")
+	got := UnusedEntries(configs...)
+	if len(got) != 1 || got[0].Filter != "Legacy" || got[0].Pos.File != path || got[0].Config != 0 {
+		t.Errorf("UnusedEntries() = %v, want Legacy of the root with the first configuration", got)
+	}
+}
@@ -0,0 +1,156 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change.
+const contextLines = 3
+
+// op represents a single line of an edit script.
+type op struct {
+	kind byte // ' ' for unchanged, '-' for deleted and '+' for inserted lines
+	line string
+	a, b int // Line index in the old and new text before this line
+}
+
+// Unified returns the unified diff between the old and new text, labeled with
+// the given names. It returns an empty string if both texts are equal.
+func Unified(oldName string, newName string, oldText string, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+
+	ops := editScript(splitLines(oldText), splitLines(newText))
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "--- %s\n+++ %s\n", oldName, newName)
+
+	for i := 0; i < len(ops); {
+		// Skip unchanged lines up to the next change
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		// Merge following changes separated by at most twice the context into the hunk
+		last := i
+		for j := i + 1; j < len(ops) && j-last <= 2*contextLines+1; j++ {
+			if ops[j].kind != ' ' {
+				last = j
+			}
+		}
+
+		end := min(last+contextLines+1, len(ops))
+		writeHunk(sb, ops[max(i-contextLines, 0):end])
+		i = end
+	}
+
+	return sb.String()
+}
+
+// splitLines splits the text into lines including their line terminator, so a
+// last line without one differs from the same line with one.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// editScript computes a minimal edit script from a to b based on their longest
+// common subsequence. The common prefix and suffix are trimmed first, so the table
+// of the subsequence only covers the changed region, e.g. a few lines of a large
+// generated file.
+func editScript(a []string, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	for i := range prefix {
+		ops = append(ops, op{kind: ' ', line: a[i], a: i, b: i})
+	}
+	ops = lcsScript(ops, a[:len(a)-suffix], b[:len(b)-suffix], prefix)
+	for k := suffix; k > 0; k-- {
+		i, j := len(a)-k, len(b)-k
+		ops = append(ops, op{kind: ' ', line: a[i], a: i, b: j})
+	}
+	return ops
+}
+
+// lcsScript appends the edit script from a[start:] to b[start:] to ops.
+func lcsScript(ops []op, a []string, b []string, start int) []op {
+	n, m := len(a)-start, len(b)-start
+
+	// lcs[i][j] is the length of the longest common subsequence of a[start+i:] and b[start+j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[start+i] == b[start+j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[start+i] == b[start+j]:
+			ops = append(ops, op{kind: ' ', line: a[start+i], a: start + i, b: start + j})
+			i, j = i+1, j+1
+		case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+			// Prefer deletions, so they are listed before insertions
+			ops = append(ops, op{kind: '-', line: a[start+i], a: start + i, b: start + j})
+			i++
+		default:
+			ops = append(ops, op{kind: '+', line: b[start+j], a: start + i, b: start + j})
+			j++
+		}
+	}
+
+	return ops
+}
+
+// writeHunk writes a single hunk with its header.
+func writeHunk(sb *strings.Builder, ops []op) {
+	var aLen, bLen int
+	for _, o := range ops {
+		if o.kind != '+' {
+			aLen++
+		}
+		if o.kind != '-' {
+			bLen++
+		}
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(ops[0].a, aLen), hunkRange(ops[0].b, bLen))
+	for _, o := range ops {
+		sb.WriteByte(o.kind)
+		sb.WriteString(o.line)
+		if !strings.HasSuffix(o.line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the line range of a hunk. Empty ranges refer to the line
+// before the hunk.
+func hunkRange(start int, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
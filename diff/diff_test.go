@@ -0,0 +1,120 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// numbered returns the lines 1 to n, replacing the lines in changed with "x".
+func numbered(n int, changed ...int) string {
+	sb := &strings.Builder{}
+	for i := 1; i <= n; i++ {
+		line := fmt.Sprint(i)
+		for _, c := range changed {
+			if c == i {
+				line = "x"
+			}
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{
+			name: "equal",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+			want: "",
+		},
+		{
+			name: "change",
+			old:  "a\nb\nc\n",
+			new:  "a\nx\nc\n",
+			want: "@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			name: "create",
+			old:  "",
+			new:  "a\nb\n",
+			want: "@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "remove",
+			old:  "a\n",
+			new:  "",
+			want: "@@ -1,1 +0,0 @@\n-a\n",
+		},
+		{
+			name: "insert at start",
+			old:  "b\nc\n",
+			new:  "a\nb\nc\n",
+			want: "@@ -1,2 +1,3 @@\n+a\n b\n c\n",
+		},
+		{
+			name: "insert into repeated lines",
+			old:  "a\na\n",
+			new:  "a\na\na\n",
+			want: "@@ -1,2 +1,3 @@\n a\n a\n+a\n",
+		},
+		{
+			name: "missing newline in new text",
+			old:  "a\nb\n",
+			new:  "a\nb",
+			want: "@@ -1,2 +1,2 @@\n a\n-b\n+b\n\\ No newline at end of file\n",
+		},
+		{
+			name: "missing newline in old text",
+			old:  "a\nb",
+			new:  "a\nb\n",
+			want: "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name: "context limited to three lines",
+			old:  numbered(10),
+			new:  numbered(10, 5),
+			want: "@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+x\n 6\n 7\n 8\n",
+		},
+		{
+			name: "changes separated by twice the context are merged",
+			old:  numbered(20),
+			new:  numbered(20, 5, 12),
+			want: "@@ -2,14 +2,14 @@\n 2\n 3\n 4\n-5\n+x\n 6\n 7\n 8\n 9\n 10\n 11\n-12\n+x\n 13\n 14\n 15\n",
+		},
+		{
+			name: "changes separated by more than twice the context are split",
+			old:  numbered(20),
+			new:  numbered(20, 5, 13),
+			want: "@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+x\n 6\n 7\n 8\n" +
+				"@@ -10,7 +10,7 @@\n 10\n 11\n 12\n-13\n+x\n 14\n 15\n 16\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want
+			if want != "" {
+				want = "--- a/f\n+++ b/f\n" + want
+			}
+			if got := Unified("a/f", "b/f", tt.old, tt.new); got != want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestUnifiedLargeText(t *testing.T) {
+	// The table of the longest common subsequence of the whole texts would not fit
+	// into memory, only the changed lines are compared
+	old, new := numbered(100000), numbered(100000, 50000, 50002)
+	want := "--- a/f\n+++ b/f\n@@ -49997,9 +49997,9 @@\n 49997\n 49998\n 49999\n-50000\n+x\n 50001\n-50002\n+x\n 50003\n 50004\n 50005\n"
+	if got := Unified("a/f", "b/f", old, new); got != want {
+		t.Errorf("Unified() =\n%s\nwant\n%s", got, want)
+	}
+}
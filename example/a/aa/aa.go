@@ -1,5 +1,7 @@
 package aa
 
+import strs "strings"
+
 // MyEnum is an example enumeration type
 type MyEnum int
 
@@ -16,3 +18,11 @@ var MyVar = "Hello, World!" // MyVar
 func SayHello() {
 	println(MyVar)
 }
+
+// Describe writes a description of the enumeration value to the builder.
+func Describe(sb *strs.Builder, e MyEnum) {
+	sb.WriteString(strs.Repeat("*", int(e)))
+}
+
+// Ignore discards the given values.
+func Ignore(any, ...any) {}
@@ -14,3 +14,31 @@ func SumAll[T int | float64](values ...T) T {
 	}
 	return total
 }
+
+// Number is a constraint for numeric types.
+type Number interface {
+	~int | ~float64
+}
+
+// Pair holds two values of arbitrary types.
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// Set is a set of numbers.
+type Set[T Number] map[T]struct{}
+
+// Zero returns the zero value of the type of the given value.
+func Zero[T Number](_ T) T {
+	var zero T
+	return zero
+}
+
+// Precision is the number of decimal places used when printing numbers.
+var Precision = 2
+
+// New returns a pointer to a new zero value of type T.
+func New[T any]() *T {
+	return new(T)
+}
@@ -0,0 +1,11 @@
+package a
+
+import "example.com/example/a/aa"
+
+// SayHello prints a greeting message using MyVar, followed by an empty line.
+//
+// It is declared by hand and therefore takes precedence over aa.SayHello.
+func SayHello() {
+	aa.SayHello()
+	println()
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/marvinpeter95/reexporter/reexport"
+)
+
+// explain prints how the declarations with the original name symbol are handled by
+// the configurations generating the package in dir.
+func explain(dir string, symbol string) error {
+	explanations, err := reexport.Explain(dir, symbol)
+	for _, x := range explanations {
+		fmt.Printf("%s (%s) at %s\n", x.Symbol, x.Kind, x.Pos)
+		fmt.Printf("  export %s at %s:\n", x.Export.Import, x.Export.Pos)
+		for _, step := range x.Steps {
+			fmt.Printf("    %s\n", step)
+		}
+		if x.Name == "" {
+			fmt.Println("    not exported")
+		}
+	}
+
+	if len(explanations) == 0 && err == nil {
+		fmt.Fprintf(os.Stderr, "no declaration named %s found in the packages re-exported by %s\n", symbol, dir)
+	}
+	return err
+}
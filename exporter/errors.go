@@ -0,0 +1,46 @@
+package exporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/marvinpeter95/reexporter/config"
+	"golang.org/x/tools/go/packages"
+)
+
+// ExportError is returned when processing an export configuration fails.
+type ExportError struct {
+	Export config.Export // The export configuration which failed.
+	Err    error         // The underlying error.
+}
+
+// Error returns the error message prefixed with the position of the export configuration.
+func (e *ExportError) Error() string {
+	return fmt.Sprintf("%s: import %q: %v", e.Export.Pos, e.Export.Import, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ExportError) Unwrap() error {
+	return e.Err
+}
+
+// LoadError is returned when packages could not be loaded. It holds the errors
+// reported for the packages and their dependencies.
+type LoadError struct {
+	Errors []packages.Error
+}
+
+// Error returns all package errors, one per line.
+func (e *LoadError) Error() string {
+	sb := &strings.Builder{}
+	sb.WriteString(ErrLoadingPackages.Error())
+	for _, err := range e.Errors {
+		sb.WriteString("\n" + err.Error())
+	}
+	return sb.String()
+}
+
+// Unwrap returns ErrLoadingPackages.
+func (e *LoadError) Unwrap() error {
+	return ErrLoadingPackages
+}
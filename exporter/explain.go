@@ -0,0 +1,124 @@
+package exporter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/marvinpeter95/reexporter/config"
+	"golang.org/x/tools/go/packages"
+)
+
+// Explanation describes how an export configuration handled a declaration.
+type Explanation struct {
+	Export config.Export     // The export configuration
+	Symbol string            // The qualified name of the declaration (e.g. "example.com/a/aa.Client")
+	Kind   config.ExportType // The kind of the declaration
+	Pos    token.Position    // The position of the declaration
+	Steps  []string          // The decisions about the declaration, in order
+	Name   string            // The final export name, empty if not exported
+}
+
+// explainer collects explanations for the declarations named symbol.
+type explainer struct {
+	symbol  string
+	results []*Explanation
+	current *Explanation // Explanation of the declaration being added, nil if not explained
+}
+
+// Explain processes the exports like Generate without returning the generated code.
+// It returns how every declaration with the original name symbol was handled by
+// each export configuration, along with the errors of the exports.
+func (e *Exporter) Explain(symbol string) ([]Explanation, error) {
+	e.explain = &explainer{symbol: symbol}
+	defer func() { e.explain = nil }()
+
+	_, err := e.Generate()
+
+	explanations := make([]Explanation, 0, len(e.explain.results))
+	for _, x := range e.explain.results {
+		explanations = append(explanations, *x)
+	}
+	return explanations, err
+}
+
+// explainFile records the declarations named like the explained symbol in a file
+// excluded by the export configuration.
+func (e *Exporter) explainFile(pkg *packages.Package, export *config.Export, file *ast.File, fileName string) {
+	if e.explain == nil {
+		return
+	}
+
+	for _, ident := range topLevelIdents(file) {
+		if ident.Name != e.explain.symbol {
+			continue
+		}
+		_, reason := export.ExplainFile(fileName)
+		e.explain.results = append(e.explain.results, &Explanation{
+			Export: *export,
+			Symbol: pkg.PkgPath + "." + ident.Name,
+			Kind:   objectKind(pkg.TypesInfo.Defs[ident]),
+			Pos:    e.fset.Position(ident.Pos()),
+			Steps:  []string{reason},
+		})
+	}
+}
+
+// exportAs determines the export name of a declaration like config.Export.ExportAs.
+// The decision is recorded if the declaration is explained.
+func (e *Exporter) exportAs(pkg *packages.Package, export *config.Export, fileName string, ident *ast.Ident, exportType config.ExportType) (string, bool, error) {
+	if e.explain == nil {
+		return export.ExportAs(ident, exportType, pkg.Name)
+	}
+
+	e.explain.current = nil
+	if ident.Name != e.explain.symbol {
+		return export.ExportAs(ident, exportType, pkg.Name)
+	}
+
+	d, err := export.Explain(ident, exportType, pkg.Name)
+	_, reason := export.ExplainFile(fileName)
+	x := &Explanation{
+		Export: *export,
+		Symbol: pkg.PkgPath + "." + ident.Name,
+		Kind:   exportType,
+		Pos:    e.fset.Position(ident.Pos()),
+		Steps:  append([]string{reason}, d.Steps...),
+	}
+	e.explain.results = append(e.explain.results, x)
+	if d.Exported {
+		e.explain.current = x
+	}
+	return d.Name, d.Exported, err
+}
+
+// explainStep records a decision about the declaration being added, if it is explained.
+func (e *Exporter) explainStep(format string, args ...any) {
+	if e.explain != nil && e.explain.current != nil {
+		e.explain.current.Steps = append(e.explain.current.Steps, fmt.Sprintf(format, args...))
+	}
+}
+
+// explainExported records the final name of the declaration being added, if it is
+// explained.
+func (e *Exporter) explainExported(name string) {
+	if e.explain != nil && e.explain.current != nil {
+		e.explain.current.Name = name
+		e.explainStep("exported as %s", name)
+	}
+}
+
+// objectKind returns the export type of a package-level object.
+func objectKind(obj types.Object) config.ExportType {
+	switch obj.(type) {
+	case *types.TypeName:
+		return config.ExportTypeType
+	case *types.Const:
+		return config.ExportTypeConstant
+	case *types.Func:
+		return config.ExportTypeFunction
+	default:
+		return config.ExportTypeVariable
+	}
+}
@@ -1,27 +1,66 @@
 package exporter
 
 import (
+	"bytes"
+	"context"
 	_ "embed"
 	"errors"
+	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
+	"go/version"
+	"log/slog"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/marvinpeter95/reexporter/config"
 	"github.com/marvinpeter95/reexporter/exporter/exports"
+	"github.com/marvinpeter95/reexporter/module"
+	"golang.org/x/mod/modfile"
 	"golang.org/x/tools/go/packages"
 )
 
-var ErrLoadingPackages = errors.New("failed to load packages")
+var (
+	ErrLoadingPackages         = errors.New("failed to load packages")
+	ErrGenericAliasUnsupported = errors.New("generic type aliases require go 1.24 or later")
+)
+
+// The first line of all generated files consists of the header prefix, the
+// configuration file the code was generated from, if known, and the header suffix
+// (e.g. "// Code generated by "exporter" from exported.yaml. DO NOT EDIT."), see
+// templates/exported.gotpl.
+const (
+	generatedPrefix = "// Code generated by \"exporter\""
+	generatedSuffix = ". DO NOT EDIT."
+)
+
+// minGenericAliasVersion is the first Go version supporting generic type aliases.
+const minGenericAliasVersion = "go1.24"
 
 // Exporter represents the code exporter.
 type Exporter struct {
-	Exports []config.Export  // The export configurations.
-	Dir     string           // The dictory of the main module where the go.mod is located.
-	PkgName string           // The package name for the generated code.
-	data    *exports.Exports // Holds the collected export data.
-	fset    *token.FileSet   // Keep track of positions for file-based exclusion.
+	Exports    []config.Export  // The export configurations.
+	Dir        string           // The dictory of the main module where the go.mod is located.
+	PkgName    string           // The import path of the package for the generated code.
+	Logger     *slog.Logger     // Logger for warnings, slog.Default() is used if nil.
+	Context    context.Context  // Context for cancelling package loading if Loader is nil (optional).
+	BuildFlags []string         // Build flags passed to the build system if Loader is nil (e.g. "-tags=foo").
+	Loader     *Loader          // Loads the packages, shared with other exporters (optional).
+	Config     string           // Path of the configuration file, recorded in the generated files (optional).
+	Shared     []string         // Output files generated into the same package by other exporters, declared like hand-written files (optional).
+	stale      []string         // Files generated by a previous run which are no longer generated.
+	source     string           // Config relative to the directory of the generated package.
+	pkgPattern string           // Directory pattern of the generated package relative to Dir (e.g. "./api").
+	loader     *Loader          // The loader used by the current run.
+	data       *exports.Exports // Holds the collected export data of the current output file.
+	fset       *token.FileSet   // Keep track of positions for file-based exclusion.
+	goVer      string           // The go directive of the main module (e.g. "go1.24").
+	explain    *explainer       // Collects explanations for a symbol, nil if not explaining.
 }
 
 // New creates a new Exporter with the given configuration.
@@ -29,91 +68,526 @@ func New(exports []config.Export, dir string, pkgName string) *Exporter {
 	return &Exporter{Exports: exports, Dir: dir, PkgName: pkgName}
 }
 
-// Generate generates the exported code based on the configuration.
-func (e *Exporter) Generate() (string, error) {
-	e.data = exports.New(filepath.Base(e.PkgName))
-	e.fset = token.NewFileSet()
+// logger returns the logger for warnings.
+func (e *Exporter) logger() *slog.Logger {
+	if e.Logger != nil {
+		return e.Logger
+	}
+	return slog.Default()
+}
+
+// File represents a generated file.
+type File struct {
+	Name    string   // The file name, relative to the package directory.
+	Code    string   // The generated code.
+	Symbols []Symbol // The symbols exported by the file, grouped by kind.
+}
+
+// Symbol represents a symbol exported by a generated file.
+type Symbol struct {
+	Name   string            // The export name.
+	Origin string            // The qualified name of the re-exported symbol (e.g. "example.com/a/aa.Client").
+	Kind   config.ExportType // The kind of the symbol.
+}
+
+// Generate generates the exported code based on the configuration. Exports are
+// grouped by their output file, a file is generated for each output in order of
+// first appearance. All files share the same package namespace.
+func (e *Exporter) Generate() ([]File, error) {
+	// Load all packages of the exports at once, unless a shared loader is used.
+	e.loader = e.Loader
+	if e.loader == nil {
+		e.loader = NewLoader()
+		e.loader.Context = e.Context
+		e.loader.BuildFlags = e.BuildFlags
+	}
+	e.fset = e.loader.fset
+
+	// Announce the packages and determine the language version of the main module.
+	mod, pattern, err := e.preload(e.loader)
+	if err != nil {
+		return nil, err
+	}
+	e.pkgPattern = pattern
+	e.goVer = ""
+	if mod.Go != nil {
+		e.goVer = "go" + mod.Go.Version
+	}
+	e.source, err = e.relConfig(filepath.Join(e.Dir, filepath.FromSlash(pattern)))
+	if err != nil {
+		return nil, err
+	}
+
+	// Group the exports by output file.
+	outputs := e.Outputs()
+	groups := make(map[string][]config.Export)
+	for _, export := range e.Exports {
+		output := e.outputName(export.Output)
+		groups[output] = append(groups[output], export)
+	}
+
+	// Hand-written declarations take precedence over re-exports.
+	decls := exports.NewDeclarations()
+	name, err := e.declareManual(decls, pattern, outputs)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = PackageName(e.PkgName)
+	}
+
+	// Process all exports, even if some fail, to report all errors at once.
+	var errs []error
+	files := make([]File, 0, len(outputs))
+	for _, output := range outputs {
+		e.data = exports.New(name, decls)
+		e.data.Source = e.source
+
+		failed := false
+		for _, export := range groups[output] {
+			if err := e.processExport(export); err != nil {
+				errs = append(errs, &ExportError{Export: export, Err: err})
+				failed = true
+			}
+		}
+		if failed {
+			continue
+		}
+
+		// Render the template with the collected data.
+		codeStr, err := renderTemplate(e.data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// Format the generated code.
+		formatted, err := formatCode(codeStr)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		files = append(files, File{Name: output, Code: formatted, Symbols: e.symbols()})
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return files, nil
+}
+
+// symbols returns the symbols exported by the current output file.
+func (e *Exporter) symbols() []Symbol {
+	paths := make(map[string]string, len(e.data.Imports))
+	for _, imp := range e.data.Imports {
+		paths[imp.Qualifier()] = imp.Path
+	}
+
+	var symbols []Symbol
+	add := func(kind config.ExportType, export exports.Export) {
+		symbols = append(symbols, Symbol{Name: export.ExportName, Origin: paths[export.Package] + "." + export.Name, Kind: kind})
+	}
+	for _, t := range e.data.Types {
+		add(config.ExportTypeType, t.Export)
+	}
+	for _, v := range e.data.Variables {
+		add(config.ExportTypeVariable, v.Export)
+	}
+	for _, a := range e.data.Accessors {
+		add(config.ExportTypeVariable, a.Export)
+	}
+	for _, c := range e.data.Constants {
+		add(config.ExportTypeConstant, c)
+	}
+	for _, f := range e.data.Functions {
+		add(config.ExportTypeFunction, f.Export)
+	}
+	return symbols
+}
 
+// Outputs returns the names of the files generated by the exporter, in order of
+// first appearance.
+func (e *Exporter) Outputs() []string {
+	var outputs []string
 	for _, export := range e.Exports {
-		if err := e.processExport(export); err != nil {
-			return "", err
+		if output := e.outputName(export.Output); !slices.Contains(outputs, output) {
+			outputs = append(outputs, output)
 		}
 	}
+	return outputs
+}
+
+// StaleFiles returns the names of the files of the package which were generated
+// from the same configuration file by a previous run, but are neither generated by
+// the exporter nor listed in Shared anymore, e.g. after an output was renamed. They
+// are found by Generate, which ignores their declarations, but does not remove
+// them. Files generated from other configuration files are never stale, since the
+// other configuration may still generate them.
+func (e *Exporter) StaleFiles() []string {
+	return e.stale
+}
 
-	// Render the template with the collected data.
-	codeStr, err := renderTemplate(e.data)
+// relConfig returns the path of the configuration file relative to the directory
+// of the generated package, or an empty string if the configuration is unknown.
+func (e *Exporter) relConfig(pkgDir string) (string, error) {
+	if e.Config == "" {
+		return "", nil
+	}
+	file, err := filepath.Abs(e.Config)
 	if err != nil {
 		return "", err
 	}
+	pkgDir, err = filepath.Abs(pkgDir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(pkgDir, file)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// outputName resolves the file name for an output setting.
+func (e *Exporter) outputName(output string) string {
+	return OutputName(output, e.PkgName)
+}
+
+// IsGenerated reports whether the code was generated by an Exporter.
+func IsGenerated(code []byte) bool {
+	_, ok := GeneratedFrom(code)
+	return ok
+}
+
+// GeneratedFrom reports whether the code was generated by an Exporter and returns
+// the slash-separated path of the configuration file it was generated from,
+// relative to the directory of the package. The path is empty if the configuration
+// is unknown.
+func GeneratedFrom(code []byte) (string, bool) {
+	line, _, _ := bytes.Cut(code, []byte("\n"))
+	return parseHeader(string(line))
+}
+
+// parseHeader parses the first line of a generated file, see GeneratedFrom.
+func parseHeader(line string) (string, bool) {
+	rest, ok := strings.CutPrefix(line, generatedPrefix)
+	if !ok {
+		return "", false
+	}
+	rest, ok = strings.CutSuffix(rest, generatedSuffix)
+	if !ok {
+		return "", false
+	}
+	if rest == "" {
+		return "", true
+	}
+	file, ok := strings.CutPrefix(rest, " from ")
+	return file, ok && file != ""
+}
+
+// OutputName resolves the file name for an output setting of the package pkgPath.
+// Names prefixed with "__" are prefixed with the package name instead (e.g. "__.go"
+// becomes "pkg.go").
+func OutputName(output string, pkgPath string) string {
+	if baseName, ok := strings.CutPrefix(output, "__"); ok {
+		return PackageName(pkgPath) + baseName
+	}
+	return output
+}
+
+// PackageName derives the name of a new package from its import path like goimports
+// assumes it: major version suffixes and a "go-" prefix are skipped, and the name
+// ends before the first character invalid in identifiers (e.g. "yaml.v3" is yaml).
+func PackageName(pkgPath string) string {
+	elems := strings.Split(pkgPath, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && isMajorVersion(name) {
+		name = elems[len(elems)-2]
+	}
+
+	name = strings.TrimPrefix(name, "go-")
+	if i := strings.IndexFunc(name, func(r rune) bool {
+		return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.ToLower(name)
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "pkg" + name
+	}
+	return name
+}
+
+// isMajorVersion reports whether elem is a major version suffix (e.g. "v2").
+func isMajorVersion(elem string) bool {
+	digits, ok := strings.CutPrefix(elem, "v")
+	return ok && digits != "" && strings.Trim(digits, "0123456789") == ""
+}
+
+// Preload announces the packages of the exports to the shared loader, so they are
+// loaded along with the packages of other exporters using the same loader.
+func (e *Exporter) Preload() error {
+	if e.Loader == nil {
+		return nil
+	}
+	_, _, err := e.preload(e.Loader)
+	return err
+}
+
+// preload announces the packages of the exports to the loader. It returns the
+// main module and the pattern of the package the code is generated for.
+func (e *Exporter) preload(l *Loader) (*modfile.File, string, error) {
+	_, mod, err := module.GetModuleFor(e.Dir)
+	if err != nil {
+		return nil, "", err
+	}
+	pattern, err := e.pattern(mod)
+	if err != nil {
+		return nil, "", err
+	}
 
-	// Format the generated code.
-	formatted, err := formatCode(codeStr)
+	l.preload(e.Dir, declMode, pattern)
+	for _, export := range e.Exports {
+		l.preload(e.Dir, exportMode, e.importPattern(pattern, export))
+	}
+	return mod, pattern, nil
+}
+
+// pattern returns the pattern of the package the code is generated for. The package
+// is loaded by directory, as it may not exist yet and must not be looked up remotely.
+// An error is returned if the package is not part of the main module.
+func (e *Exporter) pattern(mod *modfile.File) (string, error) {
+	modPath := mod.Module.Mod.Path
+	if e.PkgName == modPath {
+		return ".", nil
+	}
+	if rel, ok := strings.CutPrefix(e.PkgName, modPath+"/"); ok {
+		return "./" + rel, nil
+	}
+	return "", fmt.Errorf("package %s is outside of module %s", e.PkgName, modPath)
+}
+
+// importPattern returns the import path or pattern of the export. Imports starting
+// with "./" or "../" are relative to the generated package, whose pattern is given.
+// Like the generated package they are loaded by directory, so a missing package is
+// reported instead of being looked up remotely, unless they are outside of the main
+// module.
+func (e *Exporter) importPattern(pattern string, export config.Export) string {
+	imp := export.Import
+	if !strings.HasPrefix(imp, "./") && !strings.HasPrefix(imp, "../") {
+		return imp
+	}
+	switch rel := path.Join(pattern, imp); {
+	case rel == ".":
+		return rel
+	case rel == ".." || strings.HasPrefix(rel, "../"):
+		return path.Join(e.PkgName, imp)
+	default:
+		return "./" + rel
+	}
+}
+
+// declareManual loads the package matching pattern, which the code is generated
+// for, and declares all of its top-level identifiers in decls, except those in the
+// generated output files. Files generated from the same configuration which are
+// neither outputs nor shared are recorded as stale instead. It returns the name of the package, or
+// an empty string if the package has no Go files yet. Load errors are ignored for
+// the same reason.
+func (e *Exporter) declareManual(decls *exports.Declarations, pattern string, outputs []string) (string, error) {
+	e.stale = nil
+	pkgs, err := e.loader.load(e.Dir, declMode, pattern)
 	if err != nil {
 		return "", err
 	}
 
-	return string(formatted), nil
+	name := ""
+	for _, pkg := range pkgs {
+		if len(pkg.Syntax) > 0 {
+			name = pkg.Name
+		}
+		for _, fileAst := range pkg.Syntax {
+			fn := filepath.Base(e.fset.File(fileAst.Pos()).Name())
+			if slices.Contains(outputs, fn) {
+				continue
+			}
+			if source, ok := generatedFrom(fileAst); ok && source == e.source && !slices.Contains(e.Shared, fn) {
+				e.stale = append(e.stale, fn)
+				continue
+			}
+
+			for _, ident := range topLevelIdents(fileAst) {
+				decls.DeclareManual(ident.Name, e.fset.Position(ident.Pos()).String())
+			}
+		}
+	}
+
+	return name, nil
+}
+
+// generatedFrom reports whether the file was generated by an Exporter like
+// GeneratedFrom.
+func generatedFrom(file *ast.File) (string, bool) {
+	if len(file.Comments) == 0 || file.Comments[0].Pos() != file.FileStart {
+		return "", false
+	}
+	return parseHeader(file.Comments[0].List[0].Text)
+}
+
+// topLevelIdents returns the identifiers of all package-level declarations in the file.
+func topLevelIdents(file *ast.File) []*ast.Ident {
+	var idents []*ast.Ident
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					idents = append(idents, s.Name)
+				case *ast.ValueSpec:
+					idents = append(idents, s.Names...)
+				}
+			}
+		case *ast.FuncDecl:
+			if d.Recv == nil && d.Name.Name != "init" {
+				idents = append(idents, d.Name)
+			}
+		}
+	}
+
+	// Blank identifiers do not declare anything
+	return slices.DeleteFunc(idents, func(ident *ast.Ident) bool { return ident.Name == "_" })
 }
 
 // processExport processes a single export configuration and updates the ExportData accordingly.
 func (e *Exporter) processExport(export config.Export) error {
 	// Resolve relative imports.
-	if sub, ok := strings.CutPrefix(export.Import, "./"); ok {
-		export.Import = filepath.Join(e.PkgName, sub)
-	}
-
-	// Always add the main import.
-	e.data.AddImport(export.Import)
+	export.Import = e.importPattern(e.pkgPattern, export)
 
-	cfg := packages.Config{
-		Fset: e.fset,
-		Dir:  e.Dir,
-		Mode: packages.NeedFiles | packages.NeedSyntax | packages.NeedImports,
+	// Load the imported package, or all packages matching a pattern like "./..."
+	pkgs, err := e.loader.load(e.Dir, exportMode, export.Import)
+	if err != nil {
+		return err
 	}
 
-	// Load the imported package
-	pkgs, err := packages.Load(&cfg, export.Import)
+	// Skip packages which cannot be imported by the generated package. Packages
+	// matching a pattern are skipped silently, explicitly imported ones fail.
+	isPattern := strings.Contains(export.Import, "...")
+	pkgs = slices.DeleteFunc(pkgs, func(pkg *packages.Package) bool {
+		reason := e.notImportable(pkg)
+		if reason == "" {
+			return false
+		}
+		if isPattern {
+			e.logger().Debug("skipping package", "package", pkg.PkgPath, "reason", reason)
+		} else {
+			err = fmt.Errorf("cannot import %s: package %s", pkg.PkgPath, reason)
+		}
+		return true
+	})
 	if err != nil {
 		return err
 	}
+	slices.SortFunc(pkgs, func(a, b *packages.Package) int {
+		return strings.Compare(a.PkgPath, b.PkgPath)
+	})
 
 	// Check for errors while loading packages
-	if packages.PrintErrors(pkgs) > 0 {
-		return ErrLoadingPackages
+	var loadErr LoadError
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		loadErr.Errors = append(loadErr.Errors, pkg.Errors...)
+	})
+	if len(loadErr.Errors) > 0 {
+		return &loadErr
 	}
 
 	// Process each package
 	for _, pkg := range pkgs {
-		// Add necessary imports from the package
-		for _, imp := range pkg.Imports {
-			// Only add imports that are not part of the standard library, since those may
-			// may not be resolvable via the formatter.
-			if strings.Contains(imp.ID, ".") {
-				e.data.AddImport(imp.ID)
-			}
-		}
+		// Always add the main import, symbols are qualified by the resulting name.
+		pkgName := e.data.AddImport(pkg.PkgPath, pkg.Name)
 
 		// Inspect the AST of each file in the package
+		var inspectErr error
 		for _, fileAst := range pkg.Syntax {
 			ast.Inspect(fileAst, func(n ast.Node) bool {
-				return e.inspectAST(pkg, &export, n)
+				if inspectErr != nil {
+					return false
+				}
+				descend, err := e.inspectAST(pkg, pkgName, &export, n)
+				inspectErr = err
+				return descend
 			})
 		}
+		if inspectErr != nil {
+			return inspectErr
+		}
 	}
 
 	return nil
 }
 
+// notImportable returns why the package cannot be re-exported by the generated
+// package, or an empty string if it can.
+func (e *Exporter) notImportable(pkg *packages.Package) string {
+	switch {
+	case pkg.PkgPath == e.PkgName:
+		return "is the generated package itself"
+	case pkg.Name == "main":
+		return "is a command"
+	case !InternalAllowed(e.PkgName, pkg.PkgPath):
+		return "is internal"
+	case importsPackage(pkg, e.PkgName, make(map[string]bool)):
+		return "imports the generated package"
+	}
+	return ""
+}
+
+// InternalAllowed reports whether the package importer may import path according
+// to the rules for internal packages: path/to/internal/... may only be imported
+// by packages rooted at path/to.
+func InternalAllowed(importer string, path string) bool {
+	elems := strings.Split(path, "/")
+	for i := len(elems) - 1; i >= 0; i-- {
+		if elems[i] != "internal" {
+			continue
+		}
+		parent := strings.Join(elems[:i], "/")
+		return importer == parent || strings.HasPrefix(importer, parent+"/")
+	}
+	return true
+}
+
+// importsPackage reports whether pkg imports the package path, directly or indirectly.
+func importsPackage(pkg *packages.Package, path string, visited map[string]bool) bool {
+	for _, imp := range pkg.Imports {
+		if imp.PkgPath == path {
+			return true
+		}
+		if !visited[imp.PkgPath] {
+			visited[imp.PkgPath] = true
+			if importsPackage(imp, path, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // inspectAST inspects the AST nodes and collects exportable entities based on the export configuration.
-func (e *Exporter) inspectAST(pkg *packages.Package, export *config.Export, n ast.Node) bool {
+// It reports whether the children of n should be inspected.
+// Exported symbols are qualified by pkgName.
+func (e *Exporter) inspectAST(pkg *packages.Package, pkgName string, export *config.Export, n ast.Node) (bool, error) {
 	if n == nil {
-		return true
+		return true, nil
 	}
 	fn := e.fset.File(n.Pos()).Name()
 	fn = strings.TrimSuffix(filepath.Base(fn), ".go")
 
 	if !export.IncludeFile(fn) {
-		return true
+		if file, ok := n.(*ast.File); ok {
+			e.explainFile(pkg, export, file, fn)
+		}
+		return true, nil
 	}
 
 	switch n := n.(type) {
@@ -121,31 +595,329 @@ func (e *Exporter) inspectAST(pkg *packages.Package, export *config.Export, n as
 		for _, spec := range n.Specs {
 			switch s := spec.(type) {
 			case *ast.TypeSpec:
-				if name, ok := export.ExportAs(s.Name, config.ExportTypeType); ok {
-					e.data.AddType(name, s.Name.Name, filepath.Base(pkg.ID), exports.ParseComment(n.Doc, s.Comment))
+				name, ok, err := e.exportAs(pkg, export, fn, s.Name, config.ExportTypeType)
+				if err != nil {
+					return false, err
+				}
+				if !ok {
+					continue
+				}
+				typeParams, err := e.typeParams(pkg, s)
+				if err != nil {
+					return false, err
+				}
+				if obj, ok := pkg.TypesInfo.Defs[s.Name].(*types.TypeName); ok && e.skipUnnameable(pkg, s.Name, constraints(obj.Type())) {
+					continue
+				}
+				err = e.add(export, pkg.Name, name, func(name string) error {
+					return e.data.AddType(name, s.Name.Name, pkgName, exports.ParseComment(n.Doc, s.Comment), typeParams)
+				})
+				if err != nil {
+					return false, err
 				}
 			case *ast.ValueSpec:
+				exportType := config.ExportTypeVariable
+				if n.Tok == token.CONST {
+					exportType = config.ExportTypeConstant
+				}
 				for _, nameIdent := range s.Names {
-					exportType := config.ExportTypeVariable
-					if n.Tok == token.CONST {
-						exportType = config.ExportTypeConstant
+					name, ok, err := e.exportAs(pkg, export, fn, nameIdent, exportType)
+					if err != nil {
+						return false, err
+					}
+					if !ok {
+						continue
 					}
-					if name, ok := export.ExportAs(nameIdent, exportType); ok {
-						if exportType == config.ExportTypeVariable {
-							e.data.AddVariable(name, nameIdent.Name, filepath.Base(pkg.ID), exports.ParseComment(n.Doc, s.Comment))
-						} else if exportType == config.ExportTypeConstant {
-							e.data.AddConstant(name, nameIdent.Name, filepath.Base(pkg.ID), exports.ParseComment(n.Doc, s.Comment))
-						}
+					if exportType == config.ExportTypeVariable {
+						err = e.addVariable(pkg, pkgName, export, nameIdent, name, exports.ParseComment(n.Doc, s.Comment))
+					} else {
+						err = e.add(export, pkg.Name, name, func(name string) error {
+							return e.data.AddConstant(name, nameIdent.Name, pkgName, exports.ParseComment(n.Doc, s.Comment))
+						})
+					}
+					if err != nil {
+						return false, err
 					}
 				}
 			}
 		}
 	case *ast.FuncDecl:
-		if name, ok := export.ExportAs(n.Name, config.ExportTypeFunction); ok && n.Recv == nil {
-			e.data.AddFunction(name, n.Name.Name, filepath.Base(pkg.ID), exports.ParseComment(n.Doc, nil), exports.ParseFunctionSignature(n))
+		if n.Recv != nil {
+			return false, nil
+		}
+		name, ok, err := e.exportAs(pkg, export, fn, n.Name, config.ExportTypeFunction)
+		if err != nil || !ok {
+			return false, err
+		}
+		fn, ok := pkg.TypesInfo.Defs[n.Name].(*types.Func)
+		if !ok {
+			return false, nil
+		}
+		if e.skipUnnameable(pkg, n.Name, fn.Type()) {
+			return false, nil
+		}
+		sig := exports.ParseFunctionSignature(fn, e.data.Qualifier())
+		err = e.add(export, pkg.Name, name, func(name string) error {
+			return e.data.AddFunction(name, n.Name.Name, pkgName, exports.ParseComment(n.Doc, nil), sig)
+		})
+		if err != nil {
+			return false, err
 		}
 	default:
-		return true
+		return true, nil
 	}
-	return false
+	return false, nil
+}
+
+// add adds an export of a symbol from the package named pkgName under the given
+// name using the add function. Exports whose name is declared by hand in the package
+// are skipped, as are symbols already exported under the name by another export
+// entry. If the name is already used by another symbol, the collision strategy of
+// the export configuration decides whether the export is skipped, prefixed with
+// the name of its package or an error is returned.
+func (e *Exporter) add(export *config.Export, pkgName string, name string, add func(name string) error) error {
+	err := add(name)
+
+	var collision *exports.CollisionError
+	if !errors.As(err, &collision) {
+		if err == nil {
+			e.explainExported(name)
+		}
+		return err
+	}
+
+	// The symbol is matched by several export entries, e.g. a pattern and its package
+	if collision.Existing == collision.Origin {
+		e.logger().Debug("skipping symbol exported twice", "name", name, "symbol", collision.Origin)
+		e.explainStep("%s is already exported as %s, skipped", collision.Origin, name)
+		return nil
+	}
+
+	// Hand-written declarations always win
+	if collision.Manual {
+		e.logger().Info("skipping export declared by hand", "name", name, "symbol", collision.Origin, "declaration", collision.Existing)
+		e.explainStep("name %s is declared by hand at %s, skipped", name, collision.Existing)
+		return nil
+	}
+
+	switch export.Collisions {
+	case config.CollisionStrategyFirstWins:
+		e.logger().Info("skipping export with colliding name", "name", name, "symbol", collision.Origin, "existing", collision.Existing)
+		e.explainStep("name %s is already used by %s, skipped by collision strategy %s", name, collision.Existing, export.Collisions)
+		return nil
+	case config.CollisionStrategyPrefix:
+		e.explainStep("name %s is already used by %s, prefixed by collision strategy %s", name, collision.Existing, export.Collisions)
+		name = exportedPrefix(pkgName) + name
+		if err := add(name); err != nil {
+			return err
+		}
+		e.explainExported(name)
+		return nil
+	default:
+		e.explainStep("name %s is already used by %s", name, collision.Existing)
+		return err
+	}
+}
+
+// addVariable adds a variable export using the variable mode configured for it.
+// In copy mode a warning is logged if the copied value does not share state with
+// the original variable. Variables whose type cannot be named by the accessors are
+// exported in pointer mode instead.
+func (e *Exporter) addVariable(pkg *packages.Package, pkgName string, export *config.Export, ident *ast.Ident, name string, c exports.Comment) error {
+	v, ok := pkg.TypesInfo.Defs[ident].(*types.Var)
+	if !ok {
+		return nil
+	}
+
+	mode := export.VariableMode(ident.Name)
+	if u := unnameable(v.Type()); u != nil && mode == config.VariableModeAccessor {
+		mode = config.VariableModePointer
+		e.logger().Warn(
+			"variable type cannot be named outside of its package, exporting a pointer instead of accessors",
+			"variable", pkg.PkgPath+"."+ident.Name, "type", types.TypeString(u, nil),
+		)
+	}
+	e.explainStep("variable is exported in %s mode", mode)
+
+	switch mode {
+	case config.VariableModePointer:
+		return e.add(export, pkg.Name, name+"Ptr", func(name string) error {
+			return e.data.AddVariable(name, ident.Name, pkgName, c, true)
+		})
+	case config.VariableModeAccessor:
+		typ := types.TypeString(v.Type(), e.data.Qualifier())
+		return e.add(export, pkg.Name, name, func(name string) error {
+			return e.data.AddAccessor(name, ident.Name, pkgName, c, typ)
+		})
+	default:
+		switch v.Type().Underlying().(type) {
+		case *types.Pointer, *types.Signature, *types.Map, *types.Chan:
+		default:
+			e.logger().Warn(
+				"variable is copied at initialization, later assignments are not shared; "+
+					"consider the pointer or accessor variable mode",
+				"variable", pkg.PkgPath+"."+ident.Name,
+			)
+		}
+		return e.add(export, pkg.Name, name, func(name string) error {
+			return e.data.AddVariable(name, ident.Name, pkgName, c, false)
+		})
+	}
+}
+
+// exportedPrefix turns a package name into an exported identifier prefix.
+func exportedPrefix(pkgName string) string {
+	r, size := utf8.DecodeRuneInString(pkgName)
+	return string(unicode.ToUpper(r)) + pkgName[size:]
+}
+
+// typeParams returns the type parameters of a generic type declaration, with
+// constraints qualified for use in the generated package. It returns an error
+// if the main module is too old to declare generic type aliases.
+func (e *Exporter) typeParams(pkg *packages.Package, spec *ast.TypeSpec) ([]exports.Parameter, error) {
+	if spec.TypeParams == nil {
+		return nil, nil
+	}
+
+	if version.Compare(e.goVer, minGenericAliasVersion) < 0 {
+		return nil, fmt.Errorf(
+			"%w: cannot re-export %s.%s (module declares %q); exclude it or raise the go directive",
+			ErrGenericAliasUnsupported, pkg.PkgPath, spec.Name.Name, e.goVer,
+		)
+	}
+
+	obj, ok := pkg.TypesInfo.Defs[spec.Name].(*types.TypeName)
+	if !ok {
+		return nil, nil
+	}
+	generic, ok := obj.Type().(interface{ TypeParams() *types.TypeParamList })
+	if !ok {
+		return nil, nil
+	}
+
+	return exports.ParseTypeParams(generic.TypeParams(), e.data.Qualifier()), nil
+}
+
+// skipUnnameable reports whether the declaration named by ident is skipped since
+// its type t refers to a type which cannot be named outside of its package, like
+// an unexported type. The generated code would not compile otherwise.
+func (e *Exporter) skipUnnameable(pkg *packages.Package, ident *ast.Ident, t types.Type) bool {
+	u := unnameable(t)
+	if u == nil {
+		return false
+	}
+
+	typ := types.TypeString(u, nil)
+	e.logger().Warn("skipping export referring to a type which cannot be named outside of its package", "symbol", pkg.PkgPath+"."+ident.Name, "type", typ)
+	e.explainStep("refers to %s, which cannot be named outside of its package, skipped", typ)
+	return true
+}
+
+// constraints returns the constraints of the type parameters of a generic type as
+// a signature, so they can be checked by unnameable. It returns nil for other types.
+func constraints(t types.Type) types.Type {
+	generic, ok := t.(interface{ TypeParams() *types.TypeParamList })
+	if !ok || generic.TypeParams().Len() == 0 {
+		return nil
+	}
+
+	list := generic.TypeParams()
+	vars := make([]*types.Var, list.Len())
+	for i := range list.Len() {
+		vars[i] = types.NewParam(token.NoPos, nil, "", list.At(i).Constraint())
+	}
+	return types.NewSignatureType(nil, nil, nil, types.NewTuple(vars...), nil, false)
+}
+
+// unnameable returns a type referred to by t which cannot be named outside of its
+// package: an unexported named type, or a struct or interface literal with
+// unexported fields or methods. It returns nil if all types can be named.
+func unnameable(t types.Type) types.Type {
+	switch t := t.(type) {
+	case *types.Named:
+		if obj := t.Obj(); obj.Pkg() != nil && !obj.Exported() {
+			return t
+		}
+		return unnameableList(t.TypeArgs())
+	case *types.Alias:
+		if obj := t.Obj(); obj.Pkg() != nil && !obj.Exported() {
+			return t
+		}
+		return unnameableList(t.TypeArgs())
+	case *types.Pointer:
+		return unnameable(t.Elem())
+	case *types.Slice:
+		return unnameable(t.Elem())
+	case *types.Array:
+		return unnameable(t.Elem())
+	case *types.Chan:
+		return unnameable(t.Elem())
+	case *types.Map:
+		if u := unnameable(t.Key()); u != nil {
+			return u
+		}
+		return unnameable(t.Elem())
+	case *types.Signature:
+		for i := range t.TypeParams().Len() {
+			if u := unnameable(t.TypeParams().At(i).Constraint()); u != nil {
+				return u
+			}
+		}
+		if u := unnameableTuple(t.Params()); u != nil {
+			return u
+		}
+		return unnameableTuple(t.Results())
+	case *types.Struct:
+		for i := range t.NumFields() {
+			if !t.Field(i).Exported() {
+				return t
+			}
+			if u := unnameable(t.Field(i).Type()); u != nil {
+				return u
+			}
+		}
+	case *types.Interface:
+		for i := range t.NumExplicitMethods() {
+			if !t.ExplicitMethod(i).Exported() {
+				return t
+			}
+			if u := unnameable(t.ExplicitMethod(i).Type()); u != nil {
+				return u
+			}
+		}
+		for i := range t.NumEmbeddeds() {
+			if u := unnameable(t.EmbeddedType(i)); u != nil {
+				return u
+			}
+		}
+	case *types.Union:
+		for i := range t.Len() {
+			if u := unnameable(t.Term(i).Type()); u != nil {
+				return u
+			}
+		}
+	}
+	return nil
+}
+
+// unnameableTuple returns the first type of the variables which cannot be named
+// outside of its package, or nil.
+func unnameableTuple(tuple *types.Tuple) types.Type {
+	for i := range tuple.Len() {
+		if u := unnameable(tuple.At(i).Type()); u != nil {
+			return u
+		}
+	}
+	return nil
+}
+
+// unnameableList returns the first type of the list which cannot be named outside
+// of its package, or nil.
+func unnameableList(list *types.TypeList) types.Type {
+	for i := range list.Len() {
+		if u := unnameable(list.At(i)); u != nil {
+			return u
+		}
+	}
+	return nil
 }
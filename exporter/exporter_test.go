@@ -0,0 +1,259 @@
+package exporter
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/marvinpeter95/reexporter/config"
+)
+
+// generate generates the package example.com/m/f of the module in dir, writes the
+// generated files to it and builds the module. Exports without output are written
+// to exported.go. It returns the generated code of all files and the log output.
+func generate(t *testing.T, dir string, exports ...config.Export) (string, string) {
+	t.Helper()
+
+	for i := range exports {
+		if exports[i].Output == "" {
+			exports[i].Output = "exported.go"
+		}
+	}
+	logs := &bytes.Buffer{}
+	e := New(exports, dir, "example.com/m/f")
+	e.Logger = slog.New(slog.NewTextHandler(logs, nil))
+	files, err := e.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkgDir := filepath.Join(dir, "f")
+	if err := os.MkdirAll(pkgDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	code := &strings.Builder{}
+	for _, file := range files {
+		if err := os.WriteFile(filepath.Join(pkgDir, file.Name), []byte(file.Code), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		code.WriteString(file.Code)
+	}
+
+	cmd := exec.Command("go", "build", "./...")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v\n%s\n%s", err, out, code)
+	}
+	return code.String(), logs.String()
+}
+
+func TestGenerateGenericFunctions(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.24\n",
+		"a/a.go": `package a
+
+func New[T any]() *T { return new(T) }
+
+func Map[T, U any](s []T, f func(T) U) []U { return nil }
+`,
+	})
+
+	code, _ := generate(t, dir, config.Export{Import: "example.com/m/a"})
+	for _, want := range []string{"return a.New[T]()", "return a.Map[T, U](s, f)"} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, code)
+		}
+	}
+}
+
+func TestGenerateUnnameableTypes(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.24\n",
+		"a/a.go": `package a
+
+type t struct{}
+
+type number interface{ ~int }
+
+type Exported struct{}
+
+func Get() t { return t{} }
+
+func Put(map[string][]*t) {}
+
+func Anonymous() struct{ x int } { return struct{ x int }{} }
+
+func Sum[T number](a, b T) T { return a + b }
+
+type Set[T number] map[T]bool
+
+func Keep() Exported { return Exported{} }
+
+type List[T any] []T
+`,
+	})
+
+	code, logs := generate(t, dir, config.Export{Import: "example.com/m/a"})
+	for _, name := range []string{"Get", "Put", "Anonymous", "Sum", "Set"} {
+		if regexp.MustCompile(`\b` + name + `\b`).MatchString(code) {
+			t.Errorf("generated code exports %s:\n%s", name, code)
+		}
+		if !strings.Contains(logs, "symbol=example.com/m/a."+name) {
+			t.Errorf("no warning about %s:\n%s", name, logs)
+		}
+	}
+	for _, name := range []string{"Keep", "List", "Exported"} {
+		if !regexp.MustCompile(`\b` + name + `\b`).MatchString(code) {
+			t.Errorf("generated code does not export %s:\n%s", name, code)
+		}
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	tests := []struct {
+		pkgPath string
+		want    string
+	}{
+		{"example.com/m/store", "store"},
+		{"example.com/m/store/v2", "store"},
+		{"example.com/m/v2", "m"},
+		{"v2", "v2"},
+		{"example.com/m/vendor2", "vendor2"},
+		{"github.com/goccy/go-yaml", "yaml"},
+		{"gopkg.in/yaml.v3", "yaml"},
+		{"example.com/m/store-api", "store"},
+		{"example.com/m/Store_API", "store_api"},
+		{"example.com/m/2fa", "pkg2fa"},
+		{"example.com/m/-", "pkg"},
+	}
+	for _, tt := range tests {
+		if got := PackageName(tt.pkgPath); got != tt.want {
+			t.Errorf("PackageName(%q) = %q, want %q", tt.pkgPath, got, tt.want)
+		}
+	}
+}
+
+func TestOutputName(t *testing.T) {
+	tests := []struct {
+		output  string
+		pkgPath string
+		want    string
+	}{
+		{"exported.go", "example.com/m/store", "exported.go"},
+		{"__.go", "example.com/m/store", "store.go"},
+		{"___gen.go", "example.com/m/store/v2", "store_gen.go"},
+	}
+	for _, tt := range tests {
+		if got := OutputName(tt.output, tt.pkgPath); got != tt.want {
+			t.Errorf("OutputName(%q, %q) = %q, want %q", tt.output, tt.pkgPath, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateAccessorOfUnnameableType(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.24\n",
+		"a/a.go": `package a
+
+type t struct{ n int }
+
+var Exp t
+
+var Count int
+`,
+	})
+
+	export := config.Export{Import: "example.com/m/a", Variables: config.Variables{Mode: config.VariableModeAccessor}}
+	code, logs := generate(t, dir, export)
+	for _, want := range []string{"ExpPtr = &a.Exp", "func Count() int", "func SetCount(value int)"} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, code)
+		}
+	}
+	if !strings.Contains(logs, "variable=example.com/m/a.Exp") {
+		t.Errorf("no warning about Exp:\n%s", logs)
+	}
+}
+
+func TestGenerateCollisions(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod":         "module example.com/m\n\ngo 1.24\n",
+		"q/q.go":         "package q\n\ntype Client struct{}\n",
+		"x/cache/c.go":   "package cache\n\ntype Client struct{}\n",
+		"y/cache/c.go":   "package cache\n\ntype Client struct{}\n",
+		"y/cache/new.go": "package cache\n\nfunc New() *Client { return nil }\n",
+	})
+
+	// A symbol matched by several export entries is not a collision
+	code, _ := generate(t, dir, config.Export{Import: "example.com/m/q/..."}, config.Export{Import: "example.com/m/q"})
+	if want := "Client = q.Client"; strings.Count(code, want) != 1 {
+		t.Errorf("generated code does not contain %q once:\n%s", want, code)
+	}
+
+	// Colliding names are prefixed with the name of the source package, not the
+	// alias of its import
+	prefix := config.Export{Collisions: config.CollisionStrategyPrefix}
+	x, y := prefix, prefix
+	x.Import, y.Import = "example.com/m/x/cache", "example.com/m/y/cache"
+	code, _ = generate(t, dir, x, y)
+	for _, want := range []string{"Client = cache.Client", "CacheClient = ycache.Client", "func New() *ycache.Client"} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, code)
+		}
+	}
+}
+
+func TestGenerateManualDeclarations(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.24\n",
+		"a/a.go": "package a\n\ntype Client struct{}\n\nfunc New() *Client { return nil }\n\nconst Version = 1\n",
+		"f/f.go": "package f\n\nimport \"example.com/m/a\"\n\n// New is written by hand.\nfunc New() *a.Client { return &a.Client{} }\n\nvar _ = 1\n",
+	})
+
+	code, logs := generate(t, dir, config.Export{Import: "example.com/m/a"})
+	if strings.Contains(code, "func New") {
+		t.Errorf("generated code exports New declared by hand:\n%s", code)
+	}
+	if !strings.Contains(code, "Client = a.Client") || !strings.Contains(code, "Version = a.Version") {
+		t.Errorf("generated code does not export Client and Version:\n%s", code)
+	}
+	if !strings.Contains(logs, "skipping export declared by hand") || !strings.Contains(logs, "symbol=example.com/m/a.New") {
+		t.Errorf("no message about New:\n%s", logs)
+	}
+
+	// The generated file itself is not mistaken for a declaration by hand
+	code2, _ := generate(t, dir, config.Export{Import: "example.com/m/a"})
+	if code2 != code {
+		t.Errorf("second run generated different code:\n%s\nwant:\n%s", code2, code)
+	}
+}
+
+func TestGenerateRelativeImports(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod":     "module example.com/m\n\ngo 1.24\n",
+		"a/a.go":     "package a\n\ntype Client struct{}\n",
+		"f/s/s.go":   "package s\n\ntype Server struct{}\n",
+		"f/s/t/t.go": "package t\n\ntype Token struct{}\n",
+	})
+
+	code, _ := generate(t, dir, config.Export{Import: "./s/..."}, config.Export{Import: "../a"})
+	for _, want := range []string{"Server = s.Server", "Token = t.Token", "Client = a.Client"} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, code)
+		}
+	}
+
+	// Missing packages of the module are not looked up remotely
+	t.Setenv("GOPROXY", "off")
+	e := New([]config.Export{{Import: "./nope", Output: "exported.go"}}, dir, "example.com/m/f")
+	e.Logger = slog.New(slog.DiscardHandler)
+	_, err := e.Generate()
+	if err == nil || !strings.Contains(err.Error(), "directory not found") {
+		t.Errorf("Generate() error = %v, want a missing directory", err)
+	}
+}
@@ -0,0 +1,38 @@
+package exports
+
+// Declarations tracks the names declared in the generated package. It is shared
+// by the exports of all files generated for the same package.
+type Declarations struct {
+	origins map[string]string // Origin of each declared name
+	manual  map[string]bool   // Names declared by hand in the package
+}
+
+// NewDeclarations creates an empty set of declarations.
+func NewDeclarations() *Declarations {
+	return &Declarations{
+		origins: make(map[string]string),
+		manual:  make(map[string]bool),
+	}
+}
+
+// DeclareManual records a name declared by hand in the package at the given
+// position. Exports using the name afterwards fail with a CollisionError.
+func (d *Declarations) DeclareManual(name string, pos string) {
+	d.origins[name] = pos
+	d.manual[name] = true
+}
+
+// declare records the given names as declared by the symbol origin. Nothing is
+// recorded if any of the names is taken.
+func (d *Declarations) declare(origin string, names ...string) error {
+	for _, name := range names {
+		if existing, taken := d.origins[name]; taken {
+			return &CollisionError{Name: name, Existing: existing, Origin: origin, Manual: d.manual[name]}
+		}
+	}
+
+	for _, name := range names {
+		d.origins[name] = origin
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package exports
+
+import "fmt"
+
+// CollisionError is returned when an export name is already declared by another export.
+type CollisionError struct {
+	Name     string // The colliding export name.
+	Existing string // The symbol already exported under the name (e.g. "example.com/a/aa.Client").
+	Origin   string // The symbol which could not be exported.
+	Manual   bool   // Whether the existing name is declared by hand in the package.
+}
+
+// Error returns an error message naming both colliding symbols.
+func (e *CollisionError) Error() string {
+	return fmt.Sprintf("cannot export %s as %s: name already used by %s", e.Origin, e.Name, e.Existing)
+}
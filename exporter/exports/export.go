@@ -8,6 +8,45 @@ type Export struct {
 	Comment    Comment // Associated documentation and comments.
 }
 
+// exportName returns the name to be used in the export.
+func (e Export) exportName() string {
+	return e.ExportName
+}
+
+// Value returns the expression the export is assigned from in the generated code.
+func (e Export) Value() string {
+	return e.Package + "." + e.Name
+}
+
+// VariableExport represents an exported variable.
+type VariableExport struct {
+	Export // Base export information.
+
+	Reference bool // Export a pointer to the variable instead of copying it.
+}
+
+// Value returns the expression the variable is assigned from in the generated code.
+func (e VariableExport) Value() string {
+	if e.Reference {
+		return "&" + e.Export.Value()
+	}
+	return e.Export.Value()
+}
+
+// AccessorExport represents a variable exported through getter and setter functions.
+type AccessorExport struct {
+	Export // Base export information.
+
+	Type string // The type of the variable.
+}
+
+// TypeExport represents an exported type with its type parameters.
+type TypeExport struct {
+	Export // Base export information.
+
+	TypeParams []Parameter // The type parameters of generic types.
+}
+
 // FunctionExport represents an exported function with its signature.
 type FunctionExport struct {
 	Export // Base export information.
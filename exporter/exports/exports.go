@@ -1,74 +1,159 @@
 package exports
 
 import (
+	"go/types"
 	"slices"
 	"strings"
 )
 
 // Exports holds all the collected export data.
 type Exports struct {
-	Pkg        string              // Package name
-	Imports    []string            // List of import paths
-	Types      []Export            // List of type exports
-	Variables  []Export            // List of variable exports
-	Constants  []Export            // List of constant exports
-	Functions  []FunctionExport    // List of function exports
-	importsSet map[string]struct{} // Set to track unique imports
+	Pkg        string            // Package name
+	Source     string            // Configuration file the code is generated from, relative to the package directory (optional)
+	Imports    []Import          // List of imports
+	Types      []TypeExport      // List of type exports
+	Variables  []VariableExport  // List of variable exports
+	Accessors  []AccessorExport  // List of variables exported through accessors
+	Constants  []Export          // List of constant exports
+	Functions  []FunctionExport  // List of function exports
+	importsSet map[string]int    // Index of each import path in Imports
+	namesSet   map[string]string // Import path of each used package qualifier
+	decls      *Declarations     // Names declared in the package
 }
 
-// New creates a new ExportData instance for the given package.
-func New(pkg string) *Exports {
+// New creates a new ExportData instance for the given package. Export names are
+// declared in decls, which may be shared with the exports of other files.
+func New(pkg string, decls *Declarations) *Exports {
 	return &Exports{
 		Pkg:        pkg,
-		Imports:    []string{},
-		Types:      []Export{},
-		Variables:  []Export{},
+		Imports:    []Import{},
+		Types:      []TypeExport{},
+		Variables:  []VariableExport{},
+		Accessors:  []AccessorExport{},
 		Constants:  []Export{},
 		Functions:  []FunctionExport{},
-		importsSet: make(map[string]struct{}),
+		importsSet: make(map[string]int),
+		namesSet:   make(map[string]string),
+		decls:      decls,
 	}
 }
 
-// AddImport adds a new import path if it doesn't already exist.
-func (td *Exports) AddImport(importPath string) {
-	if _, exists := td.importsSet[importPath]; !exists {
-		td.importsSet[importPath] = struct{}{}
-		td.Imports = append(td.Imports, importPath)
+// AddImport adds a new import if it doesn't already exist and returns the name the
+// package is referenced by in the generated code. If the package name is already
+// used by another import, a deterministic alias derived from the import path is
+// assigned.
+func (td *Exports) AddImport(importPath string, name string) string {
+	if i, exists := td.importsSet[importPath]; exists {
+		return td.Imports[i].Qualifier()
 	}
+
+	imp := Import{Path: importPath, Name: name}
+	if _, taken := td.namesSet[name]; taken {
+		for alias := range aliasCandidates(importPath, name) {
+			if _, taken := td.namesSet[alias]; !taken {
+				imp.Alias = alias
+				break
+			}
+		}
+	}
+
+	td.namesSet[imp.Qualifier()] = importPath
+	td.importsSet[importPath] = len(td.Imports)
+	td.Imports = append(td.Imports, imp)
+
+	return imp.Qualifier()
 }
 
-// AddType adds a new type export.
-func (td *Exports) AddType(exportName string, name string, pkg string, c Comment) {
-	td.Types = insertSortedExport(td.Types, Export{
-		ExportName: exportName,
-		Name:       name,
-		Package:    pkg,
-		Comment:    c,
+// Qualifier returns a types.Qualifier which references packages by the name
+// they are imported with in the generated file. Every package referenced through
+// the qualifier is added to the imports.
+func (td *Exports) Qualifier() types.Qualifier {
+	return func(p *types.Package) string {
+		return td.AddImport(p.Path(), p.Name())
+	}
+}
+
+// AddType adds a new type export. Generic types are passed with their type parameters.
+// It returns a CollisionError if the export name is already declared.
+func (td *Exports) AddType(exportName string, name string, pkg string, c Comment, typeParams []Parameter) error {
+	if err := td.declare(pkg, name, exportName); err != nil {
+		return err
+	}
+
+	td.Types = insertSortedExport(td.Types, TypeExport{
+		Export: Export{
+			ExportName: exportName,
+			Name:       name,
+			Package:    pkg,
+			Comment:    c,
+		},
+		TypeParams: typeParams,
 	})
+	return nil
 }
 
-// AddVariable adds a new variable export.
-func (td *Exports) AddVariable(exportName string, name string, pkg string, c Comment) {
-	td.Variables = insertSortedExport(td.Variables, Export{
-		ExportName: exportName,
-		Name:       name,
-		Package:    pkg,
-		Comment:    c,
+// AddVariable adds a new variable export. If reference is set, a pointer to the
+// variable is exported instead of a copy of its value.
+// It returns a CollisionError if the export name is already declared.
+func (td *Exports) AddVariable(exportName string, name string, pkg string, c Comment, reference bool) error {
+	if err := td.declare(pkg, name, exportName); err != nil {
+		return err
+	}
+
+	td.Variables = insertSortedExport(td.Variables, VariableExport{
+		Export: Export{
+			ExportName: exportName,
+			Name:       name,
+			Package:    pkg,
+			Comment:    c,
+		},
+		Reference: reference,
+	})
+	return nil
+}
+
+// AddAccessor adds a new variable export using getter and setter functions.
+// It returns a CollisionError if the getter or setter name is already declared.
+func (td *Exports) AddAccessor(exportName string, name string, pkg string, c Comment, typ string) error {
+	if err := td.declare(pkg, name, exportName, "Set"+exportName); err != nil {
+		return err
+	}
+
+	td.Accessors = insertSortedExport(td.Accessors, AccessorExport{
+		Export: Export{
+			ExportName: exportName,
+			Name:       name,
+			Package:    pkg,
+			Comment:    c,
+		},
+		Type: typ,
 	})
+	return nil
 }
 
 // AddConstant adds a new constant export.
-func (td *Exports) AddConstant(exportName string, name string, pkg string, c Comment) {
+// It returns a CollisionError if the export name is already declared.
+func (td *Exports) AddConstant(exportName string, name string, pkg string, c Comment) error {
+	if err := td.declare(pkg, name, exportName); err != nil {
+		return err
+	}
+
 	td.Constants = insertSortedExport(td.Constants, Export{
 		ExportName: exportName,
 		Name:       name,
 		Package:    pkg,
 		Comment:    c,
 	})
+	return nil
 }
 
 // AddFunction adds a new function export.
-func (td *Exports) AddFunction(exportName string, name string, pkg string, c Comment, sig FunctionSignature) {
+// It returns a CollisionError if the export name is already declared.
+func (td *Exports) AddFunction(exportName string, name string, pkg string, c Comment, sig FunctionSignature) error {
+	if err := td.declare(pkg, name, exportName); err != nil {
+		return err
+	}
+
 	td.Functions = append(td.Functions, FunctionExport{
 		Export: Export{
 			ExportName: exportName,
@@ -78,12 +163,19 @@ func (td *Exports) AddFunction(exportName string, name string, pkg string, c Com
 		},
 		Signature: sig,
 	})
+	return nil
+}
+
+// declare declares the given export names for the symbol name from the package
+// referenced by pkg.
+func (td *Exports) declare(pkg string, name string, exportNames ...string) error {
+	return td.decls.declare(td.namesSet[pkg]+"."+name, exportNames...)
 }
 
-// insertSortedExport inserts an Export into a sorted slice while maintaining order.
-func insertSortedExport(ts []Export, t Export) []Export {
-	i, _ := slices.BinarySearchFunc(ts, t, func(a, b Export) int {
-		return strings.Compare(a.ExportName, b.ExportName)
+// insertSortedExport inserts an export into a sorted slice while maintaining order.
+func insertSortedExport[T interface{ exportName() string }](ts []T, t T) []T {
+	i, _ := slices.BinarySearchFunc(ts, t, func(a, b T) int {
+		return strings.Compare(a.exportName(), b.exportName())
 	})
 	return slices.Insert(ts, i, t)
 }
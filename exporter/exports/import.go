@@ -0,0 +1,69 @@
+package exports
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Import represents a package imported by the generated code.
+type Import struct {
+	Path  string // The import path of the package.
+	Name  string // The declared name of the package.
+	Alias string // The alias used if the name collides with another import (optional).
+}
+
+// Qualifier returns the name the package is referenced by in the generated code.
+func (i Import) Qualifier() string {
+	if i.Alias != "" {
+		return i.Alias
+	}
+	return i.Name
+}
+
+// String returns the import as it would appear in an import declaration.
+func (i Import) String() string {
+	if i.Alias != "" {
+		return i.Alias + " " + strconv.Quote(i.Path)
+	}
+	return strconv.Quote(i.Path)
+}
+
+// aliasCandidates returns the aliases to try, in order, for an import whose name
+// is already taken. Parent path elements are prepended to the name one at a time
+// (e.g. "v2/types" becomes "v2types"), followed by numbered names as last resort.
+func aliasCandidates(path string, name string) func(yield func(string) bool) {
+	return func(yield func(string) bool) {
+		elems := strings.Split(path, "/")
+		alias := sanitizeIdent(name)
+
+		// Skip the last element, it is (usually) equivalent to the name itself
+		for i := len(elems) - 2; i >= 0; i-- {
+			prefix := sanitizeIdent(elems[i])
+			if prefix == "" {
+				continue
+			}
+			alias = prefix + alias
+			if !yield(alias) {
+				return
+			}
+		}
+
+		for n := 2; ; n++ {
+			if !yield(sanitizeIdent(name) + strconv.Itoa(n)) {
+				return
+			}
+		}
+	}
+}
+
+// sanitizeIdent removes all characters from s which are not valid in an identifier
+// and lowercases the remainder.
+func sanitizeIdent(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
@@ -0,0 +1,66 @@
+package exports
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAliasCandidates(t *testing.T) {
+	tests := []struct {
+		path string
+		name string
+		want []string // First candidates
+	}{
+		{"example.com/v2/types", "types", []string{"v2types", "examplecomv2types", "types2", "types3"}},
+		{"example.com/api/v1/types", "types", []string{"v1types", "apiv1types", "examplecomapiv1types", "types2"}},
+		{"gopkg.in/yaml.v3", "yaml", []string{"gopkginyaml", "yaml2"}},
+		{"example.com/go-yaml", "yaml", []string{"examplecomyaml", "yaml2"}},
+		{"types", "types", []string{"types2", "types3"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for alias := range aliasCandidates(tt.path, tt.name) {
+			got = append(got, alias)
+			if len(got) == len(tt.want) {
+				break
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("aliasCandidates(%q, %q) = %q, want %q", tt.path, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAddImport(t *testing.T) {
+	td := New("f", NewDeclarations())
+	imports := []struct {
+		path string
+		name string
+		want string
+	}{
+		{"example.com/v1/types", "types", "types"},
+		{"example.com/v2/types", "types", "v2types"},
+		{"example.com/other/v2/types", "types", "otherv2types"},
+		{"example.com/v1/types", "types", "types"},
+		{"example.com/x/v2types", "v2types", "xv2types"},
+	}
+	for _, imp := range imports {
+		if got := td.AddImport(imp.path, imp.name); got != imp.want {
+			t.Errorf("AddImport(%q, %q) = %q, want %q", imp.path, imp.name, got, imp.want)
+		}
+	}
+
+	var got []string
+	for _, imp := range td.Imports {
+		got = append(got, imp.String())
+	}
+	want := []string{
+		`"example.com/v1/types"`,
+		`v2types "example.com/v2/types"`,
+		`otherv2types "example.com/other/v2/types"`,
+		`xv2types "example.com/x/v2types"`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("imports = %q, want %q", got, want)
+	}
+}
@@ -1,8 +1,10 @@
 package exports
 
 import (
-	"go/ast"
+	"fmt"
 	"go/types"
+	"maps"
+	"slices"
 	"strings"
 )
 
@@ -47,54 +49,87 @@ type FunctionSignature struct {
 	Results    []Parameter
 }
 
-// ParseFunctionSignature parses the function signature from an AST function declaration.
-func ParseFunctionSignature(decl *ast.FuncDecl) FunctionSignature {
-	sig := FunctionSignature{
-		Types:      []Parameter{},
-		Parameters: []Parameter{},
-		Results:    []Parameter{},
+// ParseFunctionSignature parses the signature of a type-checked function. Types
+// are rendered using the given qualifier. Parameters without a usable name are
+// given synthesized names, so they can be passed on to the function.
+func ParseFunctionSignature(fn *types.Func, q types.Qualifier) FunctionSignature {
+	// Track the package names referenced by the signature and the call
+	pkgNames := map[string]bool{q(fn.Pkg()): true}
+	trackingQ := func(p *types.Package) string {
+		name := q(p)
+		pkgNames[name] = true
+		return name
 	}
 
-	// Handle type parameters
-	if decl.Type.TypeParams != nil {
-		for _, field := range decl.Type.TypeParams.List {
-			sig.Types = append(sig.Types, parameterFromField(field)...)
-		}
+	sig := fn.Signature()
+	s := FunctionSignature{
+		Types:      ParseTypeParams(sig.TypeParams(), trackingQ),
+		Parameters: parametersFromTuple(sig.Params(), sig.Variadic(), trackingQ),
+		Results:    parametersFromTuple(sig.Results(), false, trackingQ),
 	}
+	s.nameParameters(pkgNames)
 
-	// Handle parameters
-	if decl.Type.Params != nil {
-		for _, field := range decl.Type.Params.List {
-			sig.Parameters = append(sig.Parameters, parameterFromField(field)...)
-		}
+	return s
+}
+
+// nameParameters assigns the names p0, p1, ... to unnamed and blank parameters
+// as well as to parameters shadowing one of the given package names. Named results
+// shadowing a package name are renamed to r0, r1, ... likewise. Synthesized names
+// never collide with type parameters, other parameters or package names.
+func (s *FunctionSignature) nameParameters(pkgNames map[string]bool) {
+	used := maps.Clone(pkgNames)
+	for _, p := range slices.Concat(s.Types, s.Parameters, s.Results) {
+		used[p.Name] = true
 	}
 
-	// Handle results
-	if decl.Type.Results != nil {
-		for _, field := range decl.Type.Results.List {
-			sig.Results = append(sig.Results, parameterFromField(field)...)
+	rename := func(ps []Parameter, prefix string, blank bool) {
+		for i := range ps {
+			p := &ps[i]
+			if !pkgNames[p.Name] && (!blank || p.Name != "" && p.Name != "_") {
+				continue
+			}
+
+			name := fmt.Sprintf("%s%d", prefix, i)
+			for used[name] {
+				name += "_"
+			}
+			used[name] = true
+			p.Name = name
 		}
 	}
 
-	return sig
+	rename(s.Parameters, "p", true)
+	rename(s.Results, "r", false)
 }
 
-// parameterFromField creates a Parameter from an AST field.
-func parameterFromField(field *ast.Field) []Parameter {
-	names := field.Names
+// parametersFromTuple creates parameters from a tuple of variables. If variadic
+// is set, the last variable is treated as a variadic parameter.
+func parametersFromTuple(tuple *types.Tuple, variadic bool, q types.Qualifier) []Parameter {
+	ps := make([]Parameter, tuple.Len())
+	for i := range tuple.Len() {
+		v := tuple.At(i)
+		p := Parameter{Name: v.Name()}
+
+		// Variadic parameters are typed as slices, render the element type instead
+		if slice, ok := v.Type().(*types.Slice); ok && variadic && i == tuple.Len()-1 {
+			p.Type, p.Variadic = types.TypeString(slice.Elem(), q), true
+		} else {
+			p.Type = types.TypeString(v.Type(), q)
+		}
 
-	// Handle unnamed parameters
-	if len(field.Names) == 0 {
-		names = append(names, &ast.Ident{Name: ""})
+		ps[i] = p
 	}
 
-	ps := make([]Parameter, len(names))
-	for i, nameIdent := range names {
-		p := Parameter{Name: nameIdent.Name}
-		p.Type, p.Variadic = strings.CutPrefix(types.ExprString(field.Type), "...")
+	return ps
+}
 
-		ps[i] = p
+// ParseTypeParams creates parameters from a type parameter list. Constraints are
+// rendered using the given qualifier.
+func ParseTypeParams(list *types.TypeParamList, q types.Qualifier) []Parameter {
+	ps := make([]Parameter, list.Len())
+	for i := range list.Len() {
+		tp := list.At(i)
+		ps[i] = Parameter{Name: tp.Obj().Name(), Type: types.TypeString(tp.Constraint(), q)}
 	}
-
 	return ps
 }
@@ -0,0 +1,100 @@
+package exports
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNameParameters(t *testing.T) {
+	tests := []struct {
+		name        string
+		sig         FunctionSignature
+		pkgNames    []string
+		wantParams  []string
+		wantResults []string
+	}{
+		{
+			name:       "unnamed",
+			sig:        FunctionSignature{Parameters: []Parameter{{Type: "int"}, {Type: "string"}}},
+			wantParams: []string{"p0", "p1"},
+		},
+		{
+			name:       "blank",
+			sig:        FunctionSignature{Parameters: []Parameter{{Name: "_", Type: "int"}, {Name: "x", Type: "int"}}},
+			wantParams: []string{"p0", "x"},
+		},
+		{
+			name:       "variadic",
+			sig:        FunctionSignature{Parameters: []Parameter{{Name: "format", Type: "string"}, {Type: "any", Variadic: true}}},
+			wantParams: []string{"format", "p1"},
+		},
+		{
+			name:       "shadowing a package",
+			sig:        FunctionSignature{Parameters: []Parameter{{Name: "strings", Type: "[]string"}, {Name: "sb", Type: "*strings.Builder"}}},
+			pkgNames:   []string{"strings"},
+			wantParams: []string{"p0", "sb"},
+		},
+		{
+			name:       "synthesized name taken by a parameter",
+			sig:        FunctionSignature{Parameters: []Parameter{{Type: "int"}, {Name: "p0", Type: "int"}}},
+			wantParams: []string{"p0_", "p0"},
+		},
+		{
+			name:       "synthesized name taken by a type parameter",
+			sig:        FunctionSignature{Types: []Parameter{{Name: "p0", Type: "any"}}, Parameters: []Parameter{{Type: "p0"}}},
+			wantParams: []string{"p0_"},
+		},
+		{
+			name:       "synthesized name taken by a package",
+			sig:        FunctionSignature{Parameters: []Parameter{{Type: "p0.T"}}},
+			pkgNames:   []string{"p0"},
+			wantParams: []string{"p0_"},
+		},
+		{
+			name:        "results",
+			sig:         FunctionSignature{Results: []Parameter{{Name: "strings", Type: "[]string"}, {Name: "err", Type: "error"}}},
+			pkgNames:    []string{"strings"},
+			wantResults: []string{"r0", "err"},
+		},
+		{
+			name:        "unnamed results",
+			sig:         FunctionSignature{Results: []Parameter{{Type: "int"}, {Type: "error"}}},
+			wantResults: []string{"", ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkgNames := make(map[string]bool)
+			for _, name := range tt.pkgNames {
+				pkgNames[name] = true
+			}
+			tt.sig.nameParameters(pkgNames)
+
+			if got := names(tt.sig.Parameters); !slices.Equal(got, tt.wantParams) {
+				t.Errorf("parameters = %q, want %q", got, tt.wantParams)
+			}
+			if got := names(tt.sig.Results); !slices.Equal(got, tt.wantResults) {
+				t.Errorf("results = %q, want %q", got, tt.wantResults)
+			}
+		})
+	}
+}
+
+// names returns the names of the parameters.
+func names(ps []Parameter) []string {
+	var names []string
+	for _, p := range ps {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func TestParameter(t *testing.T) {
+	p := Parameter{Name: "values", Type: "int", Variadic: true}
+	if got, want := p.Parameter(), "values ...int"; got != want {
+		t.Errorf("Parameter() = %q, want %q", got, want)
+	}
+	if got, want := p.Variable(), "values..."; got != want {
+		t.Errorf("Variable() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,235 @@
+package exporter
+
+import (
+	"context"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Load modes of the packages loaded by exporters.
+//
+// Dependencies are type-checked from source. Loading their types from export data
+// instead (NeedTypes without NeedDeps) aborts the process in go/packages with the
+// go1.27 toolchain ("internal error: package "strings" without types was imported").
+// Since the Loader loads all packages of a module in one call, the cost of
+// type-checking from source is paid once per module and run rather than once per
+// export.
+const (
+	// declMode loads the syntax of the package the code is generated for.
+	declMode = packages.NeedName | packages.NeedFiles | packages.NeedSyntax
+	// exportMode loads the re-exported packages with their types.
+	exportMode = declMode | packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedTypesInfo
+)
+
+// Loader loads the packages of exporters and shares them between exporters. The
+// packages announced with Exporter.Preload are loaded along with the next package
+// loaded from the same module directory, so all packages of a module are loaded in
+// a single call to packages.Load per load mode.
+type Loader struct {
+	Context    context.Context     // Context for cancelling package loading (optional).
+	BuildFlags []string            // Build flags passed to the build system (e.g. "-tags=foo").
+	fset       *token.FileSet      // File set of all loaded packages.
+	batches    map[batchKey]*batch // Loaded and pending patterns by module directory and load mode.
+}
+
+// batchKey identifies the packages loaded together.
+type batchKey struct {
+	dir  string
+	mode packages.LoadMode
+}
+
+// batch holds the packages loaded from a module directory with a load mode.
+type batch struct {
+	pending []string            // Patterns loaded with the next call.
+	pkgs    []*packages.Package // Root packages of all calls, by ID.
+	loaded  map[string]error    // Loaded patterns with the error of their call.
+}
+
+// NewLoader creates a new Loader.
+func NewLoader() *Loader {
+	return &Loader{fset: token.NewFileSet(), batches: make(map[batchKey]*batch)}
+}
+
+// batch returns the batch of the module directory and load mode.
+func (l *Loader) batch(dir string, mode packages.LoadMode) *batch {
+	key := batchKey{dir: dir, mode: mode}
+	b, ok := l.batches[key]
+	if !ok {
+		b = &batch{loaded: make(map[string]error)}
+		l.batches[key] = b
+	}
+	return b
+}
+
+// preload announces a pattern to be loaded with the next call for the module
+// directory and load mode.
+func (l *Loader) preload(dir string, mode packages.LoadMode, pattern string) {
+	b := l.batch(dir, mode)
+	if _, ok := b.loaded[pattern]; !ok && !slices.Contains(b.pending, pattern) {
+		b.pending = append(b.pending, pattern)
+	}
+}
+
+// load returns the root packages matching pattern, loading them along with all
+// pending patterns of the module directory and load mode if not loaded yet.
+func (l *Loader) load(dir string, mode packages.LoadMode, pattern string) ([]*packages.Package, error) {
+	l.preload(dir, mode, pattern)
+	b := l.batch(dir, mode)
+
+	if len(b.pending) > 0 {
+		// Special patterns like "all" cannot be matched against the loaded packages
+		var batched []string
+		for _, p := range b.pending {
+			if matchable(p) {
+				batched = append(batched, p)
+			} else {
+				l.loadPatterns(b, dir, mode, p)
+			}
+		}
+		l.loadPatterns(b, dir, mode, batched...)
+		b.pending = nil
+	}
+
+	if err := b.loaded[pattern]; err != nil {
+		return nil, err
+	}
+
+	var pkgs []*packages.Package
+	for _, pkg := range b.pkgs {
+		if matches(pkg, dir, pattern) {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	return pkgs, nil
+}
+
+// loadPatterns loads the patterns in a single call and adds their root packages to
+// the batch. If the call fails, the patterns are loaded one by one, so only the
+// failing patterns report the error.
+func (l *Loader) loadPatterns(b *batch, dir string, mode packages.LoadMode, patterns ...string) {
+	if len(patterns) == 0 {
+		return
+	}
+
+	cfg := packages.Config{
+		Context:    l.Context,
+		Fset:       l.fset,
+		Dir:        dir,
+		BuildFlags: l.BuildFlags,
+		Mode:       mode,
+	}
+	pkgs, err := packages.Load(&cfg, patterns...)
+	if err != nil && len(patterns) > 1 && (l.Context == nil || l.Context.Err() == nil) {
+		for _, pattern := range patterns {
+			l.loadPatterns(b, dir, mode, pattern)
+		}
+		return
+	}
+
+	for _, pkg := range pkgs {
+		if i := slices.IndexFunc(b.pkgs, func(p *packages.Package) bool { return p.ID == pkg.ID }); i >= 0 {
+			b.pkgs[i] = pkg
+		} else {
+			b.pkgs = append(b.pkgs, pkg)
+		}
+	}
+	for _, pattern := range patterns {
+		b.loaded[pattern] = err
+	}
+}
+
+// Invalidate discards the loaded packages which are pkgPath or import it, directly
+// or indirectly, after the files of pkgPath changed. Patterns which matched them
+// are pending again, so they are loaded together with the next call.
+func (l *Loader) Invalidate(pkgPath string) {
+	for key, b := range l.batches {
+		var stale []*packages.Package
+		b.pkgs = slices.DeleteFunc(b.pkgs, func(pkg *packages.Package) bool {
+			if pkg.PkgPath == pkgPath || importsPackage(pkg, pkgPath, make(map[string]bool)) {
+				stale = append(stale, pkg)
+				return true
+			}
+			return false
+		})
+
+		var patterns []string
+		for pattern := range b.loaded {
+			if slices.ContainsFunc(stale, func(pkg *packages.Package) bool { return matches(pkg, key.dir, pattern) }) {
+				patterns = append(patterns, pattern)
+			}
+		}
+		slices.Sort(patterns)
+		for _, pattern := range patterns {
+			delete(b.loaded, pattern)
+			l.preload(key.dir, key.mode, pattern)
+		}
+	}
+}
+
+// matchable reports whether the packages loaded for pattern can be told apart from
+// those of other patterns loaded in the same call.
+func matchable(pattern string) bool {
+	switch pattern {
+	case "all", "std", "cmd", "tool", "work":
+		return false
+	}
+	return true
+}
+
+// matches reports whether the root package was loaded for pattern, which is an
+// import path or a directory relative to dir, optionally containing "..." wildcards.
+func matches(pkg *packages.Package, dir string, pattern string) bool {
+	// Packages which failed to load are identified by the pattern itself
+	if pkg.ID == pattern || pkg.PkgPath == pattern {
+		return true
+	}
+
+	if !strings.HasPrefix(pattern, ".") && !filepath.IsAbs(pattern) {
+		return matchPattern(pattern, pkg.PkgPath)
+	}
+	if pkg.Dir == "" {
+		return false
+	}
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(dir, pattern)
+	}
+	return matchPattern(filepath.ToSlash(pattern), filepath.ToSlash(pkg.Dir))
+}
+
+// matchPattern reports whether the slash-separated name matches pattern like the go
+// command does: "..." matches any string, and a trailing "/..." also matches the
+// prefix itself. Vendor, testdata and directories ignored by the go command are only
+// matched literally.
+func matchPattern(pattern string, name string) bool {
+	if !strings.Contains(pattern, "...") {
+		return pattern == name
+	}
+
+	re := regexp.QuoteMeta(pattern)
+	re = strings.ReplaceAll(re, `\.\.\.`, `.*`)
+	if strings.HasSuffix(re, `/.*`) {
+		re = strings.TrimSuffix(re, `/.*`) + `(/.*)?`
+	}
+	if !regexp.MustCompile(`^` + re + `$`).MatchString(name) {
+		return false
+	}
+
+	// Check the elements matched by the wildcards
+	prefix, _, _ := strings.Cut(pattern, "...")
+	prefix = prefix[:strings.LastIndex(prefix, "/")+1]
+	rest, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return true // The prefix of a trailing "/..." itself
+	}
+	for _, elem := range strings.Split(rest, "/") {
+		if elem == "vendor" || elem == "testdata" || strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,188 @@
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"example.com/m/a", "example.com/m/a", true},
+		{"example.com/m/a", "example.com/m/ab", false},
+		{"example.com/m/...", "example.com/m", true},
+		{"example.com/m/...", "example.com/m/a/b", true},
+		{"example.com/m/...", "example.com/mm", false},
+		{"example.com/m/a...", "example.com/m/ab", true},
+		{"example.com/m/a...", "example.com/m/b", false},
+		{"example.com/.../b", "example.com/m/a/b", true},
+		{"example.com/.../b", "example.com/m/a/c", false},
+		{"example.com/m/...", "example.com/m/vendor/a", false},
+		{"example.com/m/...", "example.com/m/a/testdata", false},
+		{"example.com/m/...", "example.com/m/.hidden", false},
+		{"example.com/m/...", "example.com/m/_ignored/a", false},
+		{"example.com/m/testdata/...", "example.com/m/testdata/a", true},
+		{"example.com/m/.../a", "example.com/m/a.b/a", true},
+		{"example.com/m/a.b", "example.com/m/axb", false},
+	}
+	for _, tt := range tests {
+		if got := matchPattern(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchPattern(%q, %q) = %t, want %t", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	dir := filepath.FromSlash("/src/m")
+	pkg := &packages.Package{ID: "example.com/m/a/b", PkgPath: "example.com/m/a/b", Dir: filepath.Join(dir, "a", "b")}
+	broken := &packages.Package{ID: "./missing"}
+
+	tests := []struct {
+		pkg     *packages.Package
+		pattern string
+		want    bool
+	}{
+		{pkg, "example.com/m/a/b", true},
+		{pkg, "example.com/m/...", true},
+		{pkg, "example.com/m/a", false},
+		{pkg, "./a/b", true},
+		{pkg, "./a/...", true},
+		{pkg, "./a", false},
+		{pkg, ".", false},
+		{pkg, filepath.Join(dir, "a", "b"), true},
+		{broken, "./missing", true},
+		{broken, "./other", false},
+	}
+	for _, tt := range tests {
+		if got := matches(tt.pkg, dir, tt.pattern); got != tt.want {
+			t.Errorf("matches(%s, %q) = %t, want %t", tt.pkg.ID, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+// loaderModule is a module with package a, package b importing a and packages c
+// and c/d.
+var loaderModule = map[string]string{
+	"go.mod":   "module example.com/m\n\ngo 1.21\n",
+	"a/a.go":   "package a\n\nconst A = 1\n",
+	"b/b.go":   "package b\n\nimport \"example.com/m/a\"\n\nconst B = a.A\n",
+	"c/c.go":   "package c\n\nconst C = 1\n",
+	"c/d/d.go": "package d\n\nconst D = 1\n",
+}
+
+// writeModule writes the files, by slash-separated path, to a temporary directory
+// and returns the directory.
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// pkgPaths returns the sorted import paths of the packages.
+func pkgPaths(pkgs []*packages.Package) []string {
+	var paths []string
+	for _, pkg := range pkgs {
+		paths = append(paths, pkg.PkgPath)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func TestLoaderBatch(t *testing.T) {
+	dir := writeModule(t, loaderModule)
+	l := NewLoader()
+	l.preload(dir, exportMode, "./a")
+	l.preload(dir, exportMode, "./b")
+	l.preload(dir, exportMode, "./c/...")
+
+	b := l.batch(dir, exportMode)
+	if want := []string{"./a", "./b", "./c/..."}; !slices.Equal(b.pending, want) {
+		t.Fatalf("pending = %q, want %q", b.pending, want)
+	}
+
+	// The first load loads all pending patterns at once
+	pkgs, err := l.load(dir, exportMode, "./b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pkgPaths(pkgs), []string{"example.com/m/b"}; !slices.Equal(got, want) {
+		t.Errorf("load(./b) = %q, want %q", got, want)
+	}
+	if len(b.pending) != 0 || len(b.loaded) != 3 {
+		t.Errorf("pending = %q, loaded = %d patterns, want all 3 patterns loaded", b.pending, len(b.loaded))
+	}
+	if got, want := pkgPaths(b.pkgs), []string{"example.com/m/a", "example.com/m/b", "example.com/m/c", "example.com/m/c/d"}; !slices.Equal(got, want) {
+		t.Errorf("loaded packages = %q, want %q", got, want)
+	}
+
+	pkgs, err = l.load(dir, exportMode, "./c/...")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pkgPaths(pkgs), []string{"example.com/m/c", "example.com/m/c/d"}; !slices.Equal(got, want) {
+		t.Errorf("load(./c/...) = %q, want %q", got, want)
+	}
+
+	// Other load modes are loaded separately
+	if len(l.batch(dir, declMode).pkgs) != 0 {
+		t.Errorf("packages loaded for another load mode")
+	}
+}
+
+func TestLoaderInvalidate(t *testing.T) {
+	dir := writeModule(t, loaderModule)
+	l := NewLoader()
+	l.preload(dir, exportMode, "./a")
+	l.preload(dir, exportMode, "./b")
+	l.preload(dir, exportMode, "./c/...")
+	if _, err := l.load(dir, exportMode, "./a"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changing a invalidates a and b importing it, their patterns are loaded again
+	// with the next call
+	if err := os.WriteFile(filepath.Join(dir, "a", "a2.go"), []byte("package a\n\nconst A2 = 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	l.Invalidate("example.com/m/a")
+
+	b := l.batch(dir, exportMode)
+	if want := []string{"./a", "./b"}; !slices.Equal(b.pending, want) {
+		t.Errorf("pending = %q, want %q", b.pending, want)
+	}
+	if got, want := pkgPaths(b.pkgs), []string{"example.com/m/c", "example.com/m/c/d"}; !slices.Equal(got, want) {
+		t.Errorf("packages after Invalidate = %q, want %q", got, want)
+	}
+
+	// Loading an unaffected pattern reloads the invalidated ones
+	if _, err := l.load(dir, exportMode, "./c/..."); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.pending) != 0 {
+		t.Errorf("pending = %q after load, want none", b.pending)
+	}
+	pkgs, err := l.load(dir, exportMode, "./a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != 1 || pkgs[0].Types.Scope().Lookup("A2") == nil {
+		t.Errorf("load(./a) did not return the changed package")
+	}
+}
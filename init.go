@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/marvinpeter95/reexporter/exporter"
+	"github.com/marvinpeter95/reexporter/module"
+	"golang.org/x/tools/go/packages"
+)
+
+// generateDirective is the go:generate directive running reexporter.
+const generateDirective = "//go:generate reexporter"
+
+// initConfig writes an exported.yaml file to dir with an export entry for every
+// sub-package and adds a go:generate directive to the package if missing. Names
+// exported by several packages are reported as potential collisions.
+func initConfig(dir string) error {
+	path := filepath.Join(dir, "exported.yaml")
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+
+	baseModDir, pkgPath, err := module.PackagePath(dir)
+	if err != nil {
+		return err
+	}
+
+	// Load the package itself and all sub-packages
+	cfg := packages.Config{Dir: baseModDir, Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax}
+	pkgs, err := packages.Load(&cfg, pkgPath, pkgPath+"/...")
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(pkgs, func(a, b *packages.Package) int {
+		return strings.Compare(a.PkgPath, b.PkgPath)
+	})
+
+	var (
+		self   *packages.Package           // The package in dir, nil if it does not exist yet
+		subs   []*packages.Package         // Sub-packages which can be re-exported
+		owners = make(map[string][]string) // Packages declaring each exported name
+	)
+	for _, pkg := range pkgs {
+		switch {
+		case pkg.PkgPath == pkgPath:
+			if len(pkg.GoFiles) > 0 {
+				self = pkg
+			}
+		case pkg.Name == "main" || !exporter.InternalAllowed(pkgPath, pkg.PkgPath):
+			continue
+		default:
+			subs = append(subs, pkg)
+		}
+	}
+
+	// Collect the exported names, hand-written declarations of the package first
+	names := make(map[*packages.Package][]string)
+	for _, pkg := range append([]*packages.Package{self}, subs...) {
+		if pkg == nil {
+			continue
+		}
+		names[pkg] = exportedNames(pkg)
+		for _, name := range names[pkg] {
+			owners[name] = append(owners[name], pkg.PkgPath)
+		}
+	}
+
+	if len(subs) == 0 {
+		return fmt.Errorf("no sub-packages to re-export below %s", dir)
+	}
+
+	// Write the configuration with an export entry per sub-package
+	sb := &strings.Builder{}
+	sb.WriteString("common:\n")
+	sb.WriteString("  output: exported.go # Output file for the exports (a \"__\" prefix is replaced with the package name)\n")
+	sb.WriteString("  collisions: error # One of error, first-wins (skip later exports) or prefix (prefix later exports with their package name)\n")
+	sb.WriteString("exports:\n")
+	for _, pkg := range subs {
+		importPath := "./" + strings.TrimPrefix(pkg.PkgPath, pkgPath+"/")
+
+		// Names already declared by the package itself or a previous sub-package collide
+		var collisions []string
+		for _, name := range names[pkg] {
+			if owner := owners[name][0]; owner != pkg.PkgPath {
+				collisions = append(collisions, name)
+				slog.Warn("potential name collision", "name", name, "package", pkg.PkgPath, "existing", owner)
+			}
+		}
+
+		writeComment(sb, "  ", "Exports:", names[pkg])
+		writeComment(sb, "  ", "Collisions:", collisions)
+		fmt.Fprintf(sb, "  - import: %s\n", importPath)
+		if len(collisions) > 0 {
+			fmt.Fprintf(sb, "    exclude:\n      names: [%s] # Already declared by other packages\n", strings.Join(collisions, ", "))
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(sb.String()), 0o644); err != nil {
+		return err
+	}
+	fmt.Println(path)
+
+	return addGenerateDirective(dir, pkgPath, self)
+}
+
+// exportedNames returns the exported package-level names declared by the package
+// in order of appearance, except those of generated files.
+func exportedNames(pkg *packages.Package) []string {
+	var names []string
+	add := func(ident *ast.Ident) {
+		if ident.IsExported() && !slices.Contains(names, ident.Name) {
+			names = append(names, ident.Name)
+		}
+	}
+
+	for _, file := range pkg.Syntax {
+		if ast.IsGenerated(file) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						add(s.Name)
+					case *ast.ValueSpec:
+						for _, name := range s.Names {
+							add(name)
+						}
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					add(d.Name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// writeComment writes a YAML comment listing the names after the label, wrapped at
+// 80 columns. Nothing is written if there are no names.
+func writeComment(sb *strings.Builder, indent string, label string, names []string) {
+	if len(names) == 0 {
+		return
+	}
+
+	line := indent + "# " + label
+	for i, name := range names {
+		if i < len(names)-1 {
+			name += ","
+		}
+		if len(line)+len(name)+1 > 80 && i > 0 {
+			sb.WriteString(line + "\n")
+			line = indent + "#"
+		}
+		line += " " + name
+	}
+	sb.WriteString(line + "\n")
+}
+
+// addGenerateDirective adds a go:generate directive running reexporter to the
+// doc.go file of the package in dir, unless a file of the package already has one.
+// The package is nil if it has no Go files yet.
+func addGenerateDirective(dir string, pkgPath string, pkg *packages.Package) error {
+	name := exporter.PackageName(pkgPath)
+	if pkg != nil {
+		name = pkg.Name
+		for _, file := range pkg.GoFiles {
+			b, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			if strings.Contains(string(b), generateDirective+"\n") {
+				return nil
+			}
+		}
+	}
+
+	path := filepath.Join(dir, "doc.go")
+	b, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		b = fmt.Appendf(b, "\n%s\n", generateDirective)
+	case os.IsNotExist(err):
+		b = fmt.Appendf(nil, "// Package %s re-exports the symbols of its sub-packages.\npackage %s\n\n%s\n", name, name, generateDirective)
+	default:
+		return err
+	}
+
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return err
+	}
+	fmt.Println(path)
+	return nil
+}
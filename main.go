@@ -1,72 +1,291 @@
 package main
 
 import (
+	"bytes"
 	_ "embed"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/marvinpeter95/reexporter/config"
-	"github.com/marvinpeter95/reexporter/exporter"
-	"github.com/marvinpeter95/reexporter/module"
+	"github.com/marvinpeter95/reexporter/diff"
+	"github.com/marvinpeter95/reexporter/reexport"
+)
+
+// Exit codes of the command.
+const (
+	exitStale  = 1 // Generated files are out of date (check mode)
+	exitFailed = 2 // Processing of at least one configuration file failed
 )
 
 func main() {
-	// Start looking for exported.yaml files from the current working directory
+	check := flag.Bool("check", false, "verify that the generated files are up to date without writing them")
+	dryRun := flag.Bool("dry-run", false, "list the files which would be created, changed, left untouched or removed without writing them")
+	output := flag.String("o", "", `print the generated code of a single configuration to stdout if "-", instead of writing the files`)
+	strict := flag.Bool("strict", false, "report filters and rename rules which never match as errors instead of warnings")
+	recursive := flag.Bool("recursive", os.Getenv("GOFILE") == "", "process the subdirectories of the directories as well, unless invoked by go generate")
+	flag.Usage = usage
+	flag.Parse()
+
+	switch flag.Arg(0) {
+	case "schema":
+		switch flag.Arg(1) {
+		case "":
+			os.Stdout.Write(config.Schema)
+		case "root":
+			os.Stdout.Write(config.RootSchema)
+		default:
+			fmt.Fprintln(os.Stderr, "usage: reexporter schema [root]")
+			os.Exit(exitFailed)
+		}
+		return
+	case "init":
+		dir := "."
+		if flag.NArg() > 1 {
+			dir = flag.Arg(1)
+		}
+		if err := initConfig(dir); err != nil {
+			fmt.Fprintln(os.Stderr, "reexporter:", err)
+			os.Exit(exitFailed)
+		}
+		return
+	case "explain":
+		if flag.NArg() != 3 {
+			fmt.Fprintln(os.Stderr, "usage: reexporter explain <dir> <Symbol>")
+			os.Exit(exitFailed)
+		}
+		if err := explain(flag.Arg(1), flag.Arg(2)); err != nil {
+			fmt.Fprintln(os.Stderr, "reexporter:", err)
+			os.Exit(exitFailed)
+		}
+		return
+	case "": // No directories, the working directory is processed
+	default:
+		// Other arguments are directories, a misspelled command must not be taken for one
+		if info, err := os.Stat(flag.Arg(0)); err != nil || !info.IsDir() {
+			fmt.Fprintf(os.Stderr, "reexporter: %q is neither a command nor a directory\n", flag.Arg(0))
+			flag.Usage()
+			os.Exit(exitFailed)
+		}
+	}
+
+	if *output != "" && *output != "-" {
+		fmt.Fprintln(os.Stderr, `reexporter: only "-" (stdout) is supported as output`)
+		os.Exit(exitFailed)
+	}
+
+	// The modes exclude each other, so a combination is most likely a mistake
+	var modes []string
+	for _, mode := range []struct {
+		name string
+		set  bool
+	}{{"--check", *check}, {"--dry-run", *dryRun}, {"-o -", *output != ""}} {
+		if mode.set {
+			modes = append(modes, mode.name)
+		}
+	}
+	if len(modes) > 1 {
+		last := len(modes) - 1
+		fmt.Fprintf(os.Stderr, "reexporter: %s and %s cannot be combined\n", strings.Join(modes[:last], ", "), modes[last])
+		flag.Usage()
+		os.Exit(exitFailed)
+	}
+
+	// Relative paths are printed relative to the current working directory, which is
+	// the package directory in go generate
 	cwd, err := os.Getwd()
 	if err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, "reexporter:", err)
+		os.Exit(exitFailed)
+	}
+
+	// Start looking for exported.yaml files from the given directories, or from the
+	// current working directory
+	opts := []reexport.Option{reexport.WithRecursive(*recursive)}
+	if flag.NArg() > 0 {
+		opts = append(opts, reexport.WithDirs(flag.Args()...))
+	}
+	code := &bytes.Buffer{}
+	switch {
+	case *output != "":
+		opts = append(opts, reexport.WithOutput(code))
+	case *check, *dryRun:
+		opts = append(opts, reexport.WithDryRun())
+	}
+
+	// Errors of single configuration files do not stop the processing, to report the
+	// errors of all configuration files at once
+	result, err := reexport.Run(opts...)
+	errs, others := configErrors(err)
+	if result == nil {
+		fmt.Fprintln(os.Stderr, "reexporter:", err)
+		os.Exit(exitFailed)
+	}
+	for _, err := range others {
+		fmt.Fprintln(os.Stderr, "reexporter:", err)
+	}
+
+	if result.Configs == 0 && len(errs) == 0 && os.Getenv("GOFILE") != "" {
+		fmt.Fprintf(os.Stderr, "reexporter: no configuration for package %s in %s\n", os.Getenv("GOPACKAGE"), cwd)
+		os.Exit(exitFailed)
+	}
+
+	errs = append(errs, reportUnused(result.Unused, *strict)...)
+
+	if len(errs) > 0 {
+		printErrors(result.Configs, errs)
+	}
+	if len(errs) > 0 || len(others) > 0 {
+		os.Exit(exitFailed)
 	}
 
-	// Walk the directory tree to find exported.yaml files
-	err = filepath.WalkDir(cwd, func(path string, d fs.DirEntry, err error) error {
-		// If there's an error or it's not the exported.yaml file, skip it
-		if err != nil || d.IsDir() || d.Name() != "exported.yaml" {
-			return err
+	stale := 0
+	switch {
+	case *output != "":
+		// Print the code of a single configuration instead of writing it
+		if result.Configs != 1 {
+			fmt.Fprintf(os.Stderr, "reexporter: printing to stdout requires exactly one configuration, found %d\n", result.Configs)
+			os.Exit(exitFailed)
 		}
+		if _, err := io.Copy(os.Stdout, code); err != nil {
+			fmt.Fprintln(os.Stderr, "reexporter:", err)
+			os.Exit(exitFailed)
+		}
+	case *check:
+		// Print a diff for every file differing from the generated code
+		for _, file := range result.Files {
+			if file.Status == reexport.StatusUnchanged {
+				continue
+			}
+			if err := checkFile(cwd, file.Path, file.Code); err != nil {
+				fmt.Fprintln(os.Stderr, "reexporter:", err)
+				os.Exit(exitFailed)
+			}
+			stale++
+		}
+	case *dryRun:
+		for _, file := range result.Files {
+			fmt.Printf("%-9s %s\n", actions[file.Status], relPath(cwd, file.Path))
+		}
+	}
 
-		// Load the configuration from the exported.yaml file
-		config, err := config.FromFile(path)
-		if err != nil {
-			return err
+	if stale > 0 {
+		fmt.Fprintf(os.Stderr, "%d generated file(s) out of date, run reexporter to update them\n", stale)
+		os.Exit(exitStale)
+	}
+}
+
+// actions describes the statuses of files in dry-run mode.
+var actions = map[reexport.Status]string{
+	reexport.StatusCreated:   "create",
+	reexport.StatusChanged:   "change",
+	reexport.StatusUnchanged: "unchanged",
+	reexport.StatusRemoved:   "remove",
+}
+
+// usage prints the usage of the command and its flags.
+func usage() {
+	fmt.Fprintln(flag.CommandLine.Output(), `usage: reexporter [flags] [dir ...]
+       reexporter schema [root]
+       reexporter init [dir]
+       reexporter explain <dir> <Symbol>
+
+flags:`)
+	flag.PrintDefaults()
+}
+
+// configErrors splits the errors joined in err into the errors of the failed
+// configurations and all other errors.
+func configErrors(err error) ([]*reexport.ConfigError, []error) {
+	if err == nil {
+		return nil, nil
+	}
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return nil, []error{err}
+	}
+	var (
+		errs   []*reexport.ConfigError
+		others []error
+	)
+	for _, err := range joined.Unwrap() {
+		var ce *reexport.ConfigError
+		if errors.As(err, &ce) {
+			errs = append(errs, ce)
+		} else {
+			others = append(others, err)
 		}
+	}
+	return errs, others
+}
 
-		// Get the module information closest to the current directory
-		baseModDir, mod, err := module.GetModuleFor(filepath.Dir(path))
-		if err != nil {
-			return err
+// reportUnused logs a warning for every filter and rename rule which never matched
+// any symbol. In strict mode, they are returned as errors of the configurations
+// containing them instead.
+func reportUnused(unused []reexport.Unused, strict bool) []*reexport.ConfigError {
+	var (
+		configs []*reexport.ConfigError              // Configurations with unused entries in order of appearance
+		errs    = map[string]*reexport.ConfigError{} // Unused entries by configuration
+	)
+	for _, u := range unused {
+		if !strict {
+			slog.Warn("filter never matched", "setting", u.Setting, "filter", u.Filter, "position", u.Pos.String())
+			continue
 		}
 
-		// Determine the package path relative to the module
-		rel, err := filepath.Rel(baseModDir, filepath.Dir(path))
-		if err != nil {
-			return err
+		ce, ok := errs[u.Config]
+		if !ok {
+			ce = &reexport.ConfigError{Path: u.Config, File: u.File}
+			errs[u.Config] = ce
+			configs = append(configs, ce)
 		}
+		ce.Err = errors.Join(ce.Err, errors.New(u.String()))
+	}
+	return configs
+}
 
-		// Join the module path with the relative path to get the full package path
-		pkgPath := filepath.Join(mod.Module.Mod.Path, rel)
-		println(pkgPath)
+// relPath returns path relative to dir if possible, and path itself otherwise.
+func relPath(dir string, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil {
+		return rel
+	}
+	return path
+}
 
-		// Create a new exporter and generate the code
-		exporter := exporter.New(config.Exports, baseModDir, pkgPath)
-		code, err := exporter.Generate()
-		if err != nil {
-			return err
-		}
+// checkFile compares the generated code with the file at path and prints a unified
+// diff. Missing files are compared as empty. Paths in the diff are relative to dir.
+func checkFile(dir string, path string, code string) error {
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
 
-		// Write the generated code next to the exported.yaml file
-		outputName := config.Common.Output
-		if baseName, ok := strings.CutPrefix(outputName, "__"); ok {
-			outputName = filepath.Base(pkgPath) + baseName
-		}
-		return os.WriteFile(
-			filepath.Join(filepath.Dir(path), outputName),
-			[]byte(code),
-			0o644,
-		)
-	})
+	name, err := filepath.Rel(dir, path)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	name = filepath.ToSlash(name)
+
+	fmt.Print(diff.Unified("a/"+name, "b/"+name, string(b), code))
+	return nil
+}
+
+// printErrors prints a summary of the errors of all failed configurations. The
+// file of a configuration is omitted from the positions in its errors, since it
+// is printed as their heading.
+func printErrors(configs int, errs []*reexport.ConfigError) {
+	fmt.Fprintf(os.Stderr, "\nreexporter: %d of %d configuration(s) failed:\n", len(errs), configs)
+	for _, ce := range errs {
+		fmt.Fprintf(os.Stderr, "\n%s:\n", ce.Path)
+		for line := range strings.SplitSeq(ce.Err.Error(), "\n") {
+			fmt.Fprintf(os.Stderr, "    %s\n", strings.TrimPrefix(line, ce.File+":"))
+		}
 	}
 }
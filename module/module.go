@@ -57,6 +57,29 @@ func GetModuleFor(pkgDir string) (string, *modfile.File, error) {
 	return "", nil, ErrGoModNotFound
 }
 
+// PackagePath returns the directory of the module containing dir and the import
+// path of the package in dir. The directory does not need to exist.
+func PackagePath(dir string) (string, string, error) {
+	// Get the module information closest to the directory
+	baseModDir, mod, err := GetModuleFor(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Determine the package path relative to the module
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+	rel, err := filepath.Rel(baseModDir, absDir)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Join the module path with the relative path to get the full package path
+	return baseModDir, filepath.Join(mod.Module.Mod.Path, rel), nil
+}
+
 // LoadGoMod loads and parses a go.mod file from the specified file path.
 func loadGoMod(filePath string) (*modfile.File, error) {
 	goModData, err := os.ReadFile(filePath)
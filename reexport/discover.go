@@ -0,0 +1,95 @@
+package reexport
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/marvinpeter95/reexporter/config"
+)
+
+// configFiles returns the paths of the exported.yaml files in the directories and,
+// if recursive, their subdirectories. Vendor, testdata and hidden directories as
+// well as nested modules are skipped. Each file is only returned once.
+func configFiles(dirs []string, recursive bool) ([]string, []error) {
+	var (
+		paths []string
+		errs  []error
+		seen  = make(map[string]bool)
+	)
+	for _, dir := range dirs {
+		_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				errs = append(errs, err)
+				return nil
+			}
+
+			if d.IsDir() {
+				if path == dir {
+					return nil
+				}
+				if !recursive || skipDir(path, d.Name()) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			// Skip everything except exported.yaml files
+			if d.Name() != "exported.yaml" || seen[path] {
+				return nil
+			}
+			seen[path] = true
+			paths = append(paths, path)
+			return nil
+		})
+	}
+	return paths, errs
+}
+
+// rootFacades returns the indices of the facades of the root configuration, if not
+// nil, targeting the directories or, if recursive, their subdirectories.
+func rootFacades(root *config.Root, dirs []string, recursive bool) ([]int, []error) {
+	if root == nil {
+		return nil, nil
+	}
+
+	var (
+		facades []int
+		errs    []error
+	)
+	for i := range root.Facades {
+		targetDir, err := resolveTarget(filepath.Dir(root.Path), root.Facades[i].Target)
+		if err != nil {
+			errs = append(errs, &ConfigError{Path: facadePath(root, i), File: root.Path, Err: err})
+			continue
+		}
+		if slices.ContainsFunc(dirs, func(dir string) bool { return inDir(dir, targetDir, recursive) }) {
+			facades = append(facades, i)
+		}
+	}
+	return facades, errs
+}
+
+// facadePath describes the location of the facade with index i in the root configuration.
+func facadePath(root *config.Root, i int) string {
+	return fmt.Sprintf("%s: facades[%d]", root.Path, i)
+}
+
+// skipDir reports whether the directory at path named name is skipped when looking
+// for configuration files: vendor, testdata and hidden directories as well as the
+// root directories of nested modules.
+func skipDir(path string, name string) bool {
+	return name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") ||
+		exists(filepath.Join(path, "go.mod"))
+}
+
+// inDir reports whether path is the directory dir or, if recursive, located below it.
+func inDir(dir string, path string, recursive bool) bool {
+	if path == dir {
+		return true
+	}
+	rel, err := filepath.Rel(dir, path)
+	return recursive && err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,95 @@
+package reexport
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/marvinpeter95/reexporter/config"
+	"github.com/marvinpeter95/reexporter/exporter"
+)
+
+// Explain returns how the declarations with the original name symbol are handled by
+// the configurations generating the package in dir, which is either configured by
+// an exported.yaml file in dir or by a facade of the root configuration. Relative
+// directories are resolved against the root directory of the options, which only
+// the context, build flags and logger apply to otherwise. The explanations of all
+// configurations are returned along with their errors.
+func Explain(dir string, symbol string, opts ...Option) ([]exporter.Explanation, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	r := newRunner(o)
+
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(o.rootDir, dir)
+	}
+
+	root, err := config.FindRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect the configurations generating the package in dir by their file and
+	// location in errors
+	var (
+		paths   []string
+		names   []string
+		configs []*config.Config
+	)
+	path := filepath.Join(dir, "exported.yaml")
+	if exists(path) {
+		cfg, err := config.FromFile(path, root)
+		if err != nil {
+			return nil, err
+		}
+		paths, names, configs = append(paths, path), append(names, path), append(configs, cfg)
+	}
+	if root != nil {
+		for i := range root.Facades {
+			targetDir, err := resolveTarget(filepath.Dir(root.Path), root.Facades[i].Target)
+			if err != nil {
+				return nil, err
+			}
+			if targetDir == dir {
+				paths, names, configs = append(paths, root.Path), append(names, facadePath(root, i)), append(configs, &root.Facades[i])
+			}
+		}
+	}
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("no configuration generates the package in %s", dir)
+	}
+
+	// Load the packages of all configurations together
+	jobs := make([]job, len(configs))
+	for i, cfg := range configs {
+		e, targetDir, err := r.newExporter(paths[i], cfg)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.Preload(); err != nil {
+			return nil, err
+		}
+		jobs[i] = job{exporter: e, dir: targetDir, config: names[i]}
+	}
+	for i, err := range shareOutputs(jobs) {
+		if err != nil {
+			return nil, &ConfigError{Path: names[i], File: paths[i], Err: err}
+		}
+	}
+
+	var (
+		all  []exporter.Explanation
+		errs []error
+	)
+	for _, j := range jobs {
+		explanations, err := j.exporter.Explain(symbol)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		all = append(all, explanations...)
+	}
+
+	return all, errors.Join(errs...)
+}
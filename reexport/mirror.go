@@ -0,0 +1,134 @@
+package reexport
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/marvinpeter95/reexporter/config"
+	"github.com/marvinpeter95/reexporter/exporter"
+	"github.com/marvinpeter95/reexporter/module"
+	"golang.org/x/tools/go/packages"
+)
+
+// planMirror plans a package below the mirror destination for every package below
+// the mirror source of the configuration, using the common export configuration.
+func (r *runner) planMirror(p *plan) error {
+	dir := filepath.Dir(p.path)
+	srcDir := filepath.Join(dir, p.cfg.Mirror.Source)
+	dstDir := filepath.Join(dir, p.cfg.Mirror.Destination)
+
+	// A destination inside the source would mirror its own packages
+	if rel, err := filepath.Rel(srcDir, dstDir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("mirror: destination %s must not be inside source %s", p.cfg.Mirror.Destination, p.cfg.Mirror.Source)
+	}
+
+	baseModDir, srcPkgPath, err := module.PackagePath(srcDir)
+	if err != nil {
+		return err
+	}
+
+	// List all packages below the source directory
+	pkgs, err := packages.Load(&packages.Config{
+		Context:    r.ctx,
+		Dir:        baseModDir,
+		Mode:       packages.NeedName,
+		BuildFlags: r.buildFlags,
+	}, srcPkgPath+"/...")
+	if err != nil {
+		return err
+	}
+
+	for _, pkg := range pkgs {
+		if pkg.Name == "main" || pkg.PkgPath == "" {
+			continue
+		}
+
+		// Mirror the location of the package relative to the source directory
+		rel := strings.TrimPrefix(strings.TrimPrefix(pkg.PkgPath, srcPkgPath), "/")
+		pkgDir := filepath.Join(dstDir, filepath.FromSlash(rel))
+
+		pkgModDir, pkgPath, err := module.PackagePath(pkgDir)
+		if err != nil {
+			p.errs = append(p.errs, err)
+			continue
+		}
+
+		export := p.cfg.Common
+		export.Import = pkg.PkgPath
+		export.Pos = config.Position{File: p.path}
+
+		p.jobs = append(p.jobs, job{exporter: r.exporter(p.path, []config.Export{export}, pkgModDir, pkgPath), dir: pkgDir, config: p.name})
+	}
+
+	return nil
+}
+
+// removeStale removes files generated from the configuration below its mirror
+// destination which were not generated by the current run, skipping the source
+// directory, nested modules and directories with their own exported.yaml.
+// Directories left empty are removed as well. The files are recorded in the result
+// as removed.
+func (r *runner) removeStale(p *plan, generated map[string]bool) error {
+	dir := filepath.Dir(p.path)
+	srcDir := filepath.Join(dir, p.cfg.Mirror.Source)
+	dstDir := filepath.Join(dir, p.cfg.Mirror.Destination)
+
+	var stale []string
+	err := filepath.WalkDir(dstDir, func(path string, d fs.DirEntry, err error) error {
+		// The destination does not exist yet if nothing was written
+		if path == dstDir && errors.Is(err, fs.ErrNotExist) {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != dstDir && (path == srcDir || exists(filepath.Join(path, "go.mod"))) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Only consider output files of mirrored packages which were not generated
+		dir := filepath.Dir(path)
+		if generated[path] || exists(filepath.Join(dir, "exported.yaml")) {
+			return nil
+		}
+		_, pkgPath, err := module.PackagePath(dir)
+		if err != nil {
+			return err
+		}
+		if d.Name() != exporter.OutputName(p.cfg.Common.Output, pkgPath) {
+			return nil
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if source, ok := exporter.GeneratedFrom(b); ok && filepath.Join(dir, filepath.FromSlash(source)) == p.path {
+			stale = append(stale, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, file := range stale {
+		_, pkgPath, err := module.PackagePath(filepath.Dir(file))
+		if err != nil {
+			return err
+		}
+		if err := r.remove(p.path, pkgPath, file, dstDir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,574 @@
+// Package reexport generates packages re-exporting the symbols of other packages,
+// as configured by exported.yaml files and the root .reexporter.yaml configuration.
+// It implements the reexporter command and can be embedded in other tools.
+package reexport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/marvinpeter95/reexporter/config"
+	"github.com/marvinpeter95/reexporter/exporter"
+	"github.com/marvinpeter95/reexporter/module"
+	"golang.org/x/tools/txtar"
+)
+
+// Status describes how a file is affected by the generated code.
+type Status string
+
+const (
+	StatusCreated   Status = "created"   // The file did not exist
+	StatusChanged   Status = "changed"   // The file differs from the generated code
+	StatusUnchanged Status = "unchanged" // The file equals the generated code
+	StatusRemoved   Status = "removed"   // A stale generated file, e.g. of a renamed output or of a mirrored package whose source package disappeared
+)
+
+// File describes a generated or removed file.
+type File struct {
+	Path    string            // Absolute path of the file
+	Package string            // Import path of the generated package
+	Config  string            // Path of the configuration file the file was generated for
+	Status  Status            // How the file is affected, determined before writing it
+	Code    string            // The generated code, empty for removed files
+	Symbols []exporter.Symbol // The symbols exported by the file
+}
+
+// Result describes the outcome of Run.
+type Result struct {
+	Configs int      // Number of processed configurations, including failed ones
+	Files   []File   // Generated and removed files, in order of generation
+	Unused  []Unused // Filters and rename rules of successful configurations which never matched
+}
+
+// Unused is a filter or rename rule which never matched any symbol.
+type Unused struct {
+	config.Unused
+	Config string // Location of the first configuration containing the entry, like ConfigError.Path
+	File   string // Path of the file of that configuration
+}
+
+// ConfigError is an error which occurred while processing a configuration.
+type ConfigError struct {
+	Path string // Location of the configuration: its file, including the index of facades of the root configuration
+	File string // Path of the configuration file
+	Err  error  // The error
+}
+
+func (e *ConfigError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// options holds the settings of Run.
+type options struct {
+	ctx        context.Context
+	rootDir    string
+	dirs       []string
+	recursive  bool
+	configPath string
+	configData []byte
+	buildFlags []string
+	logger     *slog.Logger
+	dryRun     bool
+	output     io.Writer
+}
+
+// Option configures Run and Explain.
+type Option func(*options)
+
+// WithContext sets the context for cancelling the processing (default: context.Background()).
+func WithContext(ctx context.Context) Option {
+	return func(o *options) { o.ctx = ctx }
+}
+
+// WithRootDir sets the directory the root configuration is searched from and the
+// paths of WithOutput are relative to (default: the working directory).
+func WithRootDir(dir string) Option {
+	return func(o *options) { o.rootDir = dir }
+}
+
+// WithDirs sets the directories searched for exported.yaml files and targeted by
+// facades of the root configuration, relative to the root directory (default: the
+// root directory).
+func WithDirs(dirs ...string) Option {
+	return func(o *options) { o.dirs = dirs }
+}
+
+// WithRecursive sets whether the subdirectories of the directories are searched as
+// well (default: true). Vendor, testdata and hidden directories as well as nested
+// modules are always skipped.
+func WithRecursive(recursive bool) Option {
+	return func(o *options) { o.recursive = recursive }
+}
+
+// WithConfigFile processes only the configuration file at path, relative to the
+// root directory, instead of searching the directories.
+func WithConfigFile(path string) Option {
+	return func(o *options) { o.configPath, o.configData = path, nil }
+}
+
+// WithConfigData processes only the configuration parsed from data instead of
+// searching the directories. The configuration is treated as if it was read from
+// the file at path, relative to the root directory.
+func WithConfigData(path string, data []byte) Option {
+	return func(o *options) { o.configPath, o.configData = path, data }
+}
+
+// WithBuildFlags sets the build flags passed to the build system when loading
+// packages (e.g. "-tags=foo").
+func WithBuildFlags(flags ...string) Option {
+	return func(o *options) { o.buildFlags = flags }
+}
+
+// WithLogger sets the logger for progress and warnings (default: slog.Default()).
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
+
+// WithDryRun determines the status of the files without writing or removing any.
+func WithDryRun() Option {
+	return func(o *options) { o.dryRun = true }
+}
+
+// WithOutput writes the generated code to w instead of writing the files. A single
+// file is written as is, multiple files are written as a txtar archive with paths
+// relative to the root directory.
+func WithOutput(w io.Writer) Option {
+	return func(o *options) { o.output = w }
+}
+
+// newOptions applies the options to the defaults.
+func newOptions(opts []Option) (*options, error) {
+	o := &options{ctx: context.Background(), recursive: true, logger: slog.Default()}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.rootDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		o.rootDir = cwd
+	}
+	rootDir, err := filepath.Abs(o.rootDir)
+	if err != nil {
+		return nil, err
+	}
+	o.rootDir = rootDir
+
+	dirs := []string{o.rootDir}
+	if len(o.dirs) > 0 {
+		dirs = dirs[:0]
+		for _, dir := range o.dirs {
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(o.rootDir, dir)
+			}
+			dirs = append(dirs, dir)
+		}
+	}
+	o.dirs = dirs
+	if o.configPath != "" && !filepath.IsAbs(o.configPath) {
+		o.configPath = filepath.Join(o.rootDir, o.configPath)
+	}
+
+	return o, nil
+}
+
+// runner processes configurations, collecting the files of the result.
+type runner struct {
+	*options
+	result *Result
+	loader *exporter.Loader // Loads the packages of all exporters
+}
+
+// newRunner creates a runner with the options.
+func newRunner(o *options) *runner {
+	loader := exporter.NewLoader()
+	loader.Context = o.ctx
+	loader.BuildFlags = o.buildFlags
+	return &runner{options: o, result: &Result{}, loader: loader}
+}
+
+// Run generates the code of the configurations selected by the options and writes
+// the generated files. Processing continues if a configuration fails, the returned
+// error joins a *ConfigError for every failed configuration and the errors of
+// directories which could not be searched. Other errors stop the processing.
+func Run(opts ...Option) (*Result, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	r := newRunner(o)
+
+	// Look for the root configuration providing defaults and central facades
+	root, err := config.FindRoot(o.rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Plan the packages of all configurations first, so the packages they re-export
+	// are loaded together
+	var (
+		errs  []error
+		plans []*plan
+	)
+	add := func(path string, name string, load func() (*config.Config, error)) error {
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+		r.result.Configs++
+
+		p := &plan{name: name, path: path}
+		p.cfg, p.err = load()
+		if p.err == nil {
+			p.err = r.plan(p)
+		}
+		plans = append(plans, p)
+		return nil
+	}
+
+	if o.configPath != "" {
+		// Process the given configuration only
+		err = add(o.configPath, o.configPath, func() (*config.Config, error) {
+			if o.configData != nil {
+				return config.Parse(o.configPath, o.configData, root)
+			}
+			return config.FromFile(o.configPath, root)
+		})
+		if err != nil {
+			return r.result, err
+		}
+	} else {
+		// Find the exported.yaml files and the facades of the root configuration
+		// targeting the directories
+		paths, walkErrs := configFiles(o.dirs, o.recursive)
+		facades, facadeErrs := rootFacades(root, o.dirs, o.recursive)
+		errs = append(errs, walkErrs...)
+		errs = append(errs, facadeErrs...)
+		r.result.Configs += len(facadeErrs)
+
+		for _, path := range paths {
+			err := add(path, path, func() (*config.Config, error) { return config.FromFile(path, root) })
+			if err != nil {
+				return r.result, err
+			}
+		}
+		for _, i := range facades {
+			err := add(root.Path, facadePath(root, i), func() (*config.Config, error) { return &root.Facades[i], nil })
+			if err != nil {
+				return r.result, err
+			}
+		}
+	}
+
+	// Configurations generating into the same package keep each other's outputs,
+	// configurations generating the same output fail
+	var (
+		jobs   []job
+		owners []*plan
+	)
+	for _, p := range plans {
+		for _, j := range p.jobs {
+			jobs, owners = append(jobs, j), append(owners, p)
+		}
+	}
+	for i, err := range shareOutputs(jobs) {
+		owners[i].err = errors.Join(owners[i].err, err)
+	}
+
+	var done []*plan // Successfully processed configurations
+	for _, p := range plans {
+		if p.err == nil {
+			if err := r.ctx.Err(); err != nil {
+				return r.result, err
+			}
+			p.err = r.generate(p)
+		}
+		if p.err != nil {
+			errs = append(errs, &ConfigError{Path: p.name, File: p.path, Err: p.err})
+			continue
+		}
+		done = append(done, p)
+	}
+
+	cfgs := make([]*config.Config, len(done))
+	for i, p := range done {
+		cfgs[i] = p.cfg
+	}
+	for _, u := range config.UnusedEntries(cfgs...) {
+		r.result.Unused = append(r.result.Unused, Unused{Unused: u, Config: done[u.Config].name, File: done[u.Config].path})
+	}
+
+	if o.output != nil {
+		if err := r.writeOutput(); err != nil {
+			return r.result, err
+		}
+	}
+
+	return r.result, errors.Join(errs...)
+}
+
+// writes reports whether files are written and removed.
+func (r *runner) writes() bool {
+	return !r.dryRun && r.output == nil
+}
+
+// plan describes the packages generated for a configuration.
+type plan struct {
+	name string         // Location of the configuration in errors
+	path string         // Path of the configuration file
+	cfg  *config.Config // The configuration, nil if it failed to load
+	jobs []job          // The generated packages
+	errs []error        // Errors of mirrored packages which cannot be generated
+	err  error          // Error of the configuration
+}
+
+// job generates the files of a package.
+type job struct {
+	exporter *exporter.Exporter
+	dir      string // Directory of the generated package
+	config   string // Location of the configuration in errors
+}
+
+// shareOutputs tells the exporters of jobs generating into the same directory
+// about the outputs of the others, so they are not mistaken for stale files. It
+// returns an error for each job generating an output of a previous job into the
+// same directory, nil for the others.
+func shareOutputs(jobs []job) []error {
+	errs := make([]error, len(jobs))
+	for i, j := range jobs {
+		for k, other := range jobs {
+			if k == i || other.dir != j.dir {
+				continue
+			}
+			outputs := other.exporter.Outputs()
+			j.exporter.Shared = append(j.exporter.Shared, outputs...)
+
+			if k > i {
+				continue
+			}
+			for _, output := range j.exporter.Outputs() {
+				if slices.Contains(outputs, output) {
+					errs[i] = errors.Join(errs[i], fmt.Errorf("output %s in %s is also generated by %s", output, j.dir, other.config))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// plan determines the packages generated for the configuration: its target, next
+// to the configuration file by default, and the packages of a mirror configuration.
+// Their packages are preloaded by the shared loader.
+func (r *runner) plan(p *plan) error {
+	// Configurations only mirroring packages do not generate code for their own package
+	if p.cfg.Mirror == nil || len(p.cfg.Exports) > 0 {
+		e, targetDir, err := r.newExporter(p.path, p.cfg)
+		if err != nil {
+			return err
+		}
+		p.jobs = append(p.jobs, job{exporter: e, dir: targetDir, config: p.name})
+	}
+
+	if p.cfg.Mirror != nil {
+		if err := r.planMirror(p); err != nil {
+			return err
+		}
+	}
+
+	for _, j := range p.jobs {
+		if err := j.exporter.Preload(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generate generates the code of the planned packages and writes the generated
+// files. Generated files of mirrored packages whose source package disappeared are
+// removed, unless a package failed.
+func (r *runner) generate(p *plan) error {
+	errs := p.errs
+	generated := make(map[string]bool) // Paths of all generated files
+	for _, j := range p.jobs {
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+		r.logger.Info("generating package", "package", j.exporter.PkgName)
+
+		files, err := j.exporter.Generate()
+		if err == nil {
+			err = r.writeFiles(p.path, j.exporter.PkgName, j.dir, files)
+		}
+		for _, name := range j.exporter.StaleFiles() {
+			if err != nil {
+				break
+			}
+			err = r.remove(p.path, j.exporter.PkgName, filepath.Join(j.dir, name), j.dir)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, file := range files {
+			generated[filepath.Join(j.dir, file.Name)] = true
+		}
+	}
+
+	// Do not remove anything if some packages failed, their files may still be valid
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if p.cfg.Mirror != nil {
+		return r.removeStale(p, generated)
+	}
+	return nil
+}
+
+// newExporter creates an exporter for the exports of the configuration loaded from
+// the file at path. It also returns the directory of the generated package.
+func (r *runner) newExporter(path string, cfg *config.Config) (*exporter.Exporter, string, error) {
+	dir := filepath.Dir(path)
+	targetDir, err := resolveTarget(dir, cfg.Target)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := resolveImports(dir, targetDir, cfg.Exports); err != nil {
+		return nil, "", err
+	}
+
+	baseModDir, pkgPath, err := module.PackagePath(targetDir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return r.exporter(path, cfg.Exports, baseModDir, pkgPath), targetDir, nil
+}
+
+// exporter creates an exporter for the configuration file at path using the shared
+// loader and the logger of the options.
+func (r *runner) exporter(path string, exports []config.Export, dir string, pkgPath string) *exporter.Exporter {
+	e := exporter.New(exports, dir, pkgPath)
+	e.Config = path
+	e.Loader = r.loader
+	e.Logger = r.logger
+	return e
+}
+
+// writeFiles records the files generated for the package pkgPath by the
+// configuration file at path in the result and writes them to dir.
+func (r *runner) writeFiles(path string, pkgPath string, dir string, files []exporter.File) error {
+	if r.writes() {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range files {
+		filePath := filepath.Join(dir, file.Name)
+		status, err := fileStatus(filePath, file.Code)
+		if err != nil {
+			return err
+		}
+
+		r.result.Files = append(r.result.Files, File{
+			Path:    filePath,
+			Package: pkgPath,
+			Config:  path,
+			Status:  status,
+			Code:    file.Code,
+			Symbols: file.Symbols,
+		})
+
+		if r.writes() && status != StatusUnchanged {
+			if err := os.WriteFile(filePath, []byte(file.Code), 0o644); err != nil {
+				return err
+			}
+			r.loader.Invalidate(pkgPath)
+		}
+	}
+
+	return nil
+}
+
+// remove records the file at path of the package pkgPath as removed for the
+// configuration file at configPath and removes it. Directories left empty are
+// removed as well, up to stopDir.
+func (r *runner) remove(configPath string, pkgPath string, path string, stopDir string) error {
+	r.result.Files = append(r.result.Files, File{Path: path, Package: pkgPath, Config: configPath, Status: StatusRemoved})
+	if !r.writes() {
+		return nil
+	}
+
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	r.loader.Invalidate(pkgPath)
+
+	for dir := filepath.Dir(path); dir != stopDir; dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// fileStatus determines how the file at path is affected by writing code to it.
+func fileStatus(path string, code string) (Status, error) {
+	b, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return StatusCreated, nil
+	case err != nil:
+		return "", err
+	case string(b) != code:
+		return StatusChanged, nil
+	}
+	return StatusUnchanged, nil
+}
+
+// writeOutput writes the code of the generated files to the output. Removed files
+// are skipped.
+func (r *runner) writeOutput() error {
+	var files []File
+	for _, file := range r.result.Files {
+		if file.Status != StatusRemoved {
+			files = append(files, file)
+		}
+	}
+
+	if len(files) == 1 {
+		_, err := io.WriteString(r.output, files[0].Code)
+		return err
+	}
+
+	archive := &txtar.Archive{}
+	for _, file := range files {
+		name, err := filepath.Rel(r.rootDir, file.Path)
+		if err != nil {
+			return fmt.Errorf("output: %w", err)
+		}
+		archive.Files = append(archive.Files, txtar.File{Name: filepath.ToSlash(name), Data: []byte(file.Code)})
+	}
+	_, err := r.output.Write(txtar.Format(archive))
+	return err
+}
+
+// exists reports whether a file exists at path.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
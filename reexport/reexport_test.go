@@ -0,0 +1,209 @@
+package reexport
+
+import (
+	"errors"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeModule writes the files, by slash-separated path, to a temporary directory
+// and returns the directory.
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, filepath.FromSlash(name)), content)
+	}
+	return dir
+}
+
+// writeFile writes the file at path, creating its directory.
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// run runs the generator with the options in dir and fails the test on errors.
+func run(t *testing.T, dir string, opts ...Option) *Result {
+	t.Helper()
+
+	opts = append([]Option{WithRootDir(dir), WithLogger(slog.New(slog.DiscardHandler))}, opts...)
+	result, err := Run(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// statuses returns the status of the files of the result by their path relative to dir.
+func statuses(t *testing.T, dir string, result *Result) map[string]Status {
+	t.Helper()
+
+	files := make(map[string]Status)
+	for _, file := range result.Files {
+		rel, err := filepath.Rel(dir, file.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[filepath.ToSlash(rel)] = file.Status
+	}
+	return files
+}
+
+// TestExample checks that the files generated for the example module are up to date.
+func TestExample(t *testing.T) {
+	result := run(t, "../example", WithDryRun())
+	if len(result.Files) == 0 {
+		t.Fatal("no files generated")
+	}
+	for _, file := range result.Files {
+		if file.Status != StatusUnchanged {
+			t.Errorf("%s is %s, run reexporter in the example directory", file.Path, file.Status)
+		}
+	}
+}
+
+func TestRunRemovesStaleFiles(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod":          "module example.com/m\n\ngo 1.24\n",
+		"q/q.go":          "package q\n\ntype Client struct{}\n\nconst Version = 1\n",
+		"a/exported.yaml": "target: ../b\ncommon:\n  output: other.go\nexports:\n  - import: example.com/m/q\n    include:\n      kinds: [constant]\n",
+		"b/doc.go":        "package b\n",
+		"b/exported.yaml": "exports:\n  - import: example.com/m/q\n    include:\n      kinds: [type]\n",
+	})
+	result := run(t, dir)
+	want := map[string]Status{"b/exported.go": StatusCreated, "b/other.go": StatusCreated}
+	if got := statuses(t, dir, result); !maps.Equal(got, want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+
+	// Files generated from other configurations are kept in partial runs
+	bDir := filepath.Join(dir, "b")
+	for _, opts := range [][]Option{
+		{WithDirs("b"), WithRecursive(false)},
+		{WithConfigFile("b/exported.yaml")},
+		{WithRootDir(bDir)},
+	} {
+		result := run(t, dir, opts...)
+		want := map[string]Status{"b/exported.go": StatusUnchanged}
+		if got := statuses(t, dir, result); !maps.Equal(got, want) {
+			t.Errorf("files = %v, want %v", got, want)
+		}
+		if !exists(filepath.Join(bDir, "other.go")) {
+			t.Fatal("other.go generated for a/exported.yaml was removed")
+		}
+	}
+
+	// Files of renamed outputs are removed
+	writeFile(t, filepath.Join(bDir, "exported.yaml"), "common:\n  output: renamed.go\nexports:\n  - import: example.com/m/q\n    include:\n      kinds: [type]\n")
+	result = run(t, dir, WithDirs("b"))
+	want = map[string]Status{"b/renamed.go": StatusCreated, "b/exported.go": StatusRemoved}
+	if got := statuses(t, dir, result); !maps.Equal(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+	if exists(filepath.Join(bDir, "exported.go")) || !exists(filepath.Join(bDir, "other.go")) {
+		t.Error("wrong files removed")
+	}
+}
+
+func TestRunRelativeImports(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod":           "module example.com/m\n\ngo 1.24\n",
+		"a/store/store.go": "package store\n\ntype Store struct{}\n",
+		"a/exported.yaml":  "target: ../api\nexports:\n  - import: ./store\n",
+		"b/exported.yaml":  "exports:\n  - import: ./nope\n",
+		"b/doc.go":         "package b\n",
+	})
+
+	// Imports are relative to the configuration, not to the target
+	result := run(t, dir, WithDirs("a"))
+	want := map[string]Status{"api/exported.go": StatusCreated}
+	if got := statuses(t, dir, result); !maps.Equal(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+
+	// Missing packages of the module are not looked up remotely
+	t.Setenv("GOPROXY", "off")
+	_, err := Run(WithRootDir(dir), WithDirs("b"), WithLogger(slog.New(slog.DiscardHandler)))
+	if err == nil || !strings.Contains(err.Error(), "directory not found") {
+		t.Errorf("Run() error = %v, want a missing directory", err)
+	}
+}
+
+func TestRunMirrorRemovesStaleFiles(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod":                  "module example.com/m\n\ngo 1.24\n",
+		"exported.yaml":           "mirror:\n  source: ./internal\n",
+		"internal/cache/cache.go": "package cache\n\ntype Cache struct{}\n",
+		"internal/sql/sql.go":     "package sql\n\ntype DB struct{}\n",
+		"internal/store/store.go": "package store\n\ntype Store struct{}\n",
+		"q/q.go":                  "package q\n\nconst Version = 1\n",
+		"x/exported.yaml":         "target: ../legacy\nexports:\n  - import: example.com/m/q\n",
+	})
+	result := run(t, dir)
+	want := map[string]Status{
+		"cache/exported.go":  StatusCreated,
+		"legacy/exported.go": StatusCreated,
+		"sql/exported.go":    StatusCreated,
+		"store/exported.go":  StatusCreated,
+	}
+	if got := statuses(t, dir, result); !maps.Equal(got, want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+
+	// Files of removed source packages are removed, along with directories left
+	// empty, files generated from other configurations are kept
+	for _, name := range []string{"cache", "sql"} {
+		if err := os.RemoveAll(filepath.Join(dir, "internal", name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result = run(t, dir, WithConfigFile("exported.yaml"))
+	want = map[string]Status{
+		"cache/exported.go": StatusRemoved,
+		"sql/exported.go":   StatusRemoved,
+		"store/exported.go": StatusUnchanged,
+	}
+	if got := statuses(t, dir, result); !maps.Equal(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+	if !exists(filepath.Join(dir, "legacy", "exported.go")) {
+		t.Error("legacy/exported.go generated for x/exported.yaml was removed")
+	}
+	if exists(filepath.Join(dir, "sql")) {
+		t.Error("empty directory sql was not removed")
+	}
+}
+
+func TestRunDuplicateOutputs(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod":          "module example.com/m\n\ngo 1.24\n",
+		"q/q.go":          "package q\n\ntype Client struct{}\n\nconst Version = 1\n",
+		"a/exported.yaml": "target: ../b\nexports:\n  - import: example.com/m/q\n    include:\n      kinds: [constant]\n",
+		"b/doc.go":        "package b\n",
+		"b/exported.yaml": "exports:\n  - import: example.com/m/q\n    include:\n      kinds: [type]\n",
+	})
+
+	_, err := Run(WithRootDir(dir), WithDryRun(), WithLogger(slog.New(slog.DiscardHandler)))
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("Run() error = %v, want a ConfigError", err)
+	}
+	if got, want := filepath.ToSlash(cfgErr.File), "b/exported.yaml"; !strings.HasSuffix(got, want) {
+		t.Errorf("error of %s, want %s", got, want)
+	}
+	if msg := filepath.ToSlash(err.Error()); !strings.Contains(msg, "output exported.go") || !strings.Contains(msg, "also generated by "+filepath.ToSlash(dir)+"/a/exported.yaml") {
+		t.Errorf("Run() error = %v, want both configurations", err)
+	}
+}
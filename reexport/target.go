@@ -0,0 +1,93 @@
+package reexport
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/marvinpeter95/reexporter/config"
+	"github.com/marvinpeter95/reexporter/module"
+)
+
+// resolveTarget returns the directory of the package the code of the configuration
+// in dir is generated for. Targets starting with "." or "/" are directories relative
+// to dir, all other targets are import paths of the module containing dir.
+func resolveTarget(dir string, target string) (string, error) {
+	switch {
+	case target == "":
+		return dir, nil
+	case filepath.IsAbs(target):
+		return target, nil
+	case strings.HasPrefix(target, "."):
+		return filepath.Join(dir, target), nil
+	}
+
+	// Map the import path to a directory of the module
+	modDir, mod, err := module.GetModuleFor(dir)
+	if err != nil {
+		return "", err
+	}
+	modPath := mod.Module.Mod.Path
+	rel, ok := strings.CutPrefix(target, modPath)
+	if !ok || (rel != "" && !strings.HasPrefix(rel, "/")) {
+		return "", fmt.Errorf("target %q: import path is outside of module %s, use a directory instead", target, modPath)
+	}
+	targetDir := filepath.Join(modDir, filepath.FromSlash(rel))
+
+	// The directory may belong to a nested module with a different import path
+	_, pkgPath, err := module.PackagePath(targetDir)
+	if err != nil {
+		return "", err
+	}
+	if pkgPath != target {
+		return "", fmt.Errorf("target %q: directory belongs to a nested module as %s, use a directory instead", target, pkgPath)
+	}
+
+	return targetDir, nil
+}
+
+// resolveImports resolves relative imports of the exports of the configuration in
+// dir for the package generated into targetDir. If both directories belong to the
+// same module, the imports are made relative to targetDir, so the exporter loads
+// them by directory. Otherwise they are resolved against the import path of dir.
+func resolveImports(dir string, targetDir string, exports []config.Export) error {
+	modDir, pkgPath, err := module.PackagePath(dir)
+	if err != nil {
+		return err
+	}
+	targetModDir, _, err := module.PackagePath(targetDir)
+	if err != nil {
+		return err
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	absTarget, err := filepath.Abs(targetDir)
+	if err != nil {
+		return err
+	}
+
+	for i := range exports {
+		sub, ok := strings.CutPrefix(exports[i].Import, "./")
+		if !ok {
+			continue
+		}
+		if modDir != targetModDir {
+			exports[i].Import = path.Join(pkgPath, sub)
+			continue
+		}
+
+		rel, err := filepath.Rel(absTarget, filepath.Join(absDir, filepath.FromSlash(sub)))
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel != ".." && !strings.HasPrefix(rel, "../") {
+			rel = "./" + rel
+		}
+		exports[i].Import = rel
+	}
+	return nil
+}